@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var addForce bool
+
+var addCmd = &cobra.Command{
+	Use:   "add <component>...",
+	Short: "Add components to an existing Go project",
+	Args:  cobra.MatchAll(cobra.MinimumNArgs(1), cobra.OnlyValidArgs),
+	RunE:  runAdd,
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	addCmd.Long = addLongHelp()
+	for _, c := range generator.Components() {
+		addCmd.ValidArgs = append(addCmd.ValidArgs, c.Name)
+	}
+
+	addCmd.Flags().BoolVarP(&addForce, "force", "f", false, "Overwrite files that already exist")
+	addOutputFlags(addCmd)
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	// The arguments are valid components; errors from here on, like files
+	// that exist, are not about usage
+	cmd.SilenceUsage = true
+
+	r, err := newReporter(os.Stdout)
+	if err != nil {
+		return err
+	}
+	r.Banner()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := generator.DetectProject(dir)
+	if err != nil {
+		return fmt.Errorf("%w (run goscaffold add inside an existing Go module)", err)
+	}
+
+	// Display detected project
+	r.Field("Project", cfg.Name)
+	r.Field("Module", cfg.ModulePath)
+	r.Field("Layout", cfg.Template)
+
+	gen := generator.New(cfg, generator.WithOutput(generator.NewOSFS(dir)), generator.WithToolVersion(versionStr), generator.WithReporter(r))
+	if err := gen.Add(args, addForce); err != nil {
+		return fmt.Errorf("failed to add %s: %w", strings.Join(args, ", "), err)
+	}
+
+	r.Success(fmt.Sprintf("Added %s to '%s'", strings.Join(args, ", "), cfg.Name))
+	return nil
+}
+
+// addLongHelp builds the help text of the add command from the component list
+func addLongHelp() string {
+	var b strings.Builder
+	b.WriteString(`Add DevOps and quality components to the Go module in the current directory.
+
+The module path is read from go.mod and the layout (cmd/<name>, pkg/<name>
+or main.go) is detected to fill in build and run targets. Existing files are
+never overwritten unless --force is given.
+
+Components:
+`)
+	for _, c := range generator.Components() {
+		fmt.Fprintf(&b, "  %-10s - %s\n", c.Name, c.Description)
+	}
+	b.WriteString(`
+Examples:
+  goscaffold add docker ci
+  goscaffold add lint precommit --force`)
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/diff"
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/manifoldco/promptui"
+)
+
+// mergeIgnoreFiles is the rule that merges ignore files, such as the
+// .gitignore of a freshly cloned repository, unless --on-conflict says
+// otherwise
+var mergeIgnoreFiles = generator.ConflictRule{Pattern: "**/.*ignore", Policy: generator.ConflictMerge}
+
+// conflictOptions builds the conflict options of --into-existing from the
+// --on-conflict flags, each a policy for all files or pattern=policy
+func conflictOptions() (generator.ConflictOptions, error) {
+	opts := generator.ConflictOptions{Default: generator.ConflictSkip}
+	if !noInteractive {
+		opts.Default = generator.ConflictPrompt
+		opts.Prompt = promptForConflict
+	}
+
+	for _, s := range onConflict {
+		pattern, name, ok := strings.Cut(s, "=")
+		if !ok {
+			pattern, name = "", s
+		}
+		policy, err := generator.ParseConflictPolicy(name)
+		if err != nil {
+			return opts, err
+		}
+		if pattern == "" {
+			opts.Default = policy
+		} else {
+			opts.Rules = append(opts.Rules, generator.ConflictRule{Pattern: pattern, Policy: policy})
+		}
+	}
+	opts.Rules = append(opts.Rules, mergeIgnoreFiles)
+	return opts, opts.Check()
+}
+
+// promptForConflict asks what to do with an existing file, showing the
+// differences on request
+func promptForConflict(name string, existing, generated []byte) (generator.ConflictPolicy, error) {
+	type choice struct {
+		label  string
+		policy generator.ConflictPolicy
+	}
+	choices := []choice{
+		{"Keep the existing file", generator.ConflictSkip},
+		{"Overwrite it", generator.ConflictOverwrite},
+		{"Back up the existing file to " + name + ".orig and overwrite it", generator.ConflictBackup},
+	}
+	if generator.CanMerge(name) {
+		choices = append(choices, choice{"Append the missing lines to it", generator.ConflictMerge})
+	}
+	choices = append(choices, choice{"Show the differences", ""})
+
+	var items []string
+	for _, c := range choices {
+		items = append(items, c.label)
+	}
+
+	for {
+		prompt := promptui.Select{Label: name + " already exists", Items: items}
+		idx, _, err := prompt.Run()
+		if err != nil {
+			return "", err
+		}
+		if choices[idx].policy != "" {
+			return choices[idx].policy, nil
+		}
+		fmt.Print(diff.Unified("existing/"+name, "generated/"+name, string(existing), string(generated)))
+	}
+}
+
+// conflictSummary describes how an existing file was treated
+func conflictSummary(c generator.Conflict) string {
+	switch c.Policy {
+	case generator.ConflictSkip:
+		return "kept " + c.Path
+	case generator.ConflictOverwrite:
+		return "overwrote " + c.Path
+	case generator.ConflictBackup:
+		return fmt.Sprintf("overwrote %s (original in %s)", c.Path, c.Backup)
+	case generator.ConflictMerge:
+		return "merged " + c.Path
+	}
+	return string(c.Policy) + " " + c.Path
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"github.com/azrakarakaya1/goscaffold/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// applyDefaults fills in every setting whose flag was not given on the
+// command line from the user defaults, so flags always take precedence
+func applyDefaults(cmd *cobra.Command, d userconfig.Defaults) {
+	flags := cmd.Flags()
+
+	setString := func(flag string, dst *string, v string) {
+		if v != "" && !flags.Changed(flag) {
+			*dst = v
+		}
+	}
+	setBool := func(flag string, dst *bool, v *bool) {
+		if v != nil && !flags.Changed(flag) {
+			*dst = *v
+		}
+	}
+
+	setString("github", &config.GitHubUser, d.GitHub)
+	setString("host", &config.Host, d.Host)
+	// An explicit --template or --template-dir beats configured ones
+	if !flags.Changed("template") && !flags.Changed("template-dir") {
+		setString("template", &config.Template, d.Template)
+		setString("template-dir", &config.TemplateDir, d.TemplateDir)
+	}
+	setString("license", &config.License, d.License)
+	setString("author", &config.Author, d.Author)
+	setString("go-version", &config.GoVersion, d.GoVersion)
+
+	// An explicit --github or --module beats a configured prefix, except
+	// that with --vanity --github only locates the repository
+	githubWins := flags.Changed("github") && !vanity
+	if !githubWins && !flags.Changed("module") {
+		config.ModulePrefix = d.ModulePrefix
+	}
+
+	setBool("makefile", &config.IncludeMake, d.Makefile)
+	setBool("docker", &config.IncludeDocker, d.Docker)
+	setBool("ci", &config.IncludeCI, d.CI)
+	setBool("lint", &config.IncludeLint, d.Lint)
+	setBool("precommit", &config.IncludePreCommit, d.PreCommit)
+	setBool("tests", &config.IncludeTests, d.Tests)
+	setBool("git", &config.InitGit, d.Git)
+	setBool("tidy", &config.Tidy, d.Tidy)
+
+	if d.Interactive != nil && !flags.Changed("no-interactive") {
+		noInteractive = !*d.Interactive
+	}
+}
+
+// devOpsConfigured reports whether any DevOps toggle was given as a flag
+// or in the user defaults
+func devOpsConfigured(cmd *cobra.Command, d userconfig.Defaults) bool {
+	f := cmd.Flags()
+	return f.Changed("makefile") || f.Changed("docker") || f.Changed("ci") ||
+		d.Makefile != nil || d.Docker != nil || d.CI != nil
+}
+
+// qualityConfigured reports whether any quality toggle was given as a flag
+// or in the user defaults
+func qualityConfigured(cmd *cobra.Command, d userconfig.Defaults) bool {
+	f := cmd.Flags()
+	return f.Changed("lint") || f.Changed("precommit") || f.Changed("tests") ||
+		d.Lint != nil || d.PreCommit != nil || d.Tests != nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the diff command, following diff(1)
+const (
+	diffExitDrift = 1
+	diffExitError = 2
+)
+
+var diffFormat string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [path]",
+	Short: "Show how a project has drifted from its template",
+	Long: `Re-render the template of the project at path (default: the current
+directory) and print a unified diff for every file that differs from the
+template output.
+
+The configuration recorded in .goscaffold.json is used when present;
+otherwise the template and components are inferred from the project layout.
+Files the template does not produce are ignored.
+
+Exit status is 0 when the project matches its template, 1 when files have
+drifted and 2 on errors, so the command can gate CI pipelines.
+
+Examples:
+  goscaffold diff
+  goscaffold diff services/billing
+  goscaffold diff --output json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffFormat, "format", "", "Output format (text, json)")
+	diffCmd.Flags().MarkDeprecated("format", "use --output instead")
+	addOutputFlags(diffCmd)
+}
+
+// driftReport is the JSON form of the diff command output
+type driftReport struct {
+	Project  string `json:"project"`
+	Module   string `json:"module"`
+	Template string `json:"template"`
+	// Config is "manifest" when read from .goscaffold.json, else "inferred"
+	Config  string                `json:"config"`
+	Drifted int                   `json:"drifted"`
+	Files   []generator.FileDrift `json:"files"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	switch diffFormat {
+	case "":
+	case "text", "json":
+		outputFormat = diffFormat
+	default:
+		return &ExitError{Code: diffExitError, Err: fmt.Errorf("unknown format '%s' (use text or json)", diffFormat)}
+	}
+	// The diffs or, with --output json, the report go to stdout and
+	// everything else to stderr
+	r, err := newReporter(os.Stderr)
+	if err != nil {
+		return &ExitError{Code: diffExitError, Err: err}
+	}
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return &ExitError{Code: diffExitError, Err: err}
+	}
+
+	cfg, err := generator.DetectProject(dir)
+	if err != nil {
+		return &ExitError{Code: diffExitError, Err: err}
+	}
+
+	gen := generator.New(cfg, generator.WithOutput(generator.NewOSFS(dir)), generator.WithQuiet())
+	files, err := gen.Drift()
+	if err != nil {
+		return &ExitError{Code: diffExitError, Err: fmt.Errorf("failed to render template: %w", err)}
+	}
+
+	report := driftReport{
+		Project:  dir,
+		Module:   cfg.ModulePath,
+		Template: cfg.Template,
+		Config:   "inferred",
+		Files:    files,
+	}
+	if _, err := generator.LoadManifest(dir); err == nil {
+		report.Config = "manifest"
+	}
+	for _, f := range files {
+		r.Drifted(f)
+		if f.Status != generator.DriftUnchanged {
+			report.Drifted++
+		}
+	}
+
+	if report.Drifted > 0 {
+		r.Report(generator.Event{
+			Kind:    generator.EventWarning,
+			Message: fmt.Sprintf("%d of %d template file(s) drifted from %s", report.Drifted, len(files), cfg.Template),
+		})
+	} else {
+		r.Success(fmt.Sprintf("Project matches template %s", cfg.Template))
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, report); err != nil {
+			return &ExitError{Code: diffExitError, Err: err}
+		}
+	}
+
+	if report.Drifted > 0 {
+		cmd.SilenceErrors = true
+		return &ExitError{Code: diffExitDrift, Err: fmt.Errorf("%d file(s) drifted", report.Drifted)}
+	}
+	return nil
+}
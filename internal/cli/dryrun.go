@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/diff"
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+)
+
+// treeNode is a directory or file in the dry-run tree
+type treeNode struct {
+	name     string
+	dir      bool
+	size     int
+	children map[string]*treeNode
+}
+
+func (n *treeNode) child(name string, dir bool) *treeNode {
+	if n.children == nil {
+		n.children = make(map[string]*treeNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &treeNode{name: name, dir: dir}
+		n.children[name] = c
+	}
+	return c
+}
+
+// sorted returns the children with directories first, then by name
+func (n *treeNode) sorted() []*treeNode {
+	nodes := make([]*treeNode, 0, len(n.children))
+	for _, c := range n.children {
+		nodes = append(nodes, c)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].dir != nodes[j].dir {
+			return nodes[i].dir
+		}
+		return nodes[i].name < nodes[j].name
+	})
+	return nodes
+}
+
+// printTree prints the directories and files in entries as a tree with
+// sizes, below a root directory called name
+func printTree(w io.Writer, name string, entries []generator.Entry) {
+	root := &treeNode{dir: true}
+	var dirs, files, total int
+
+	for _, e := range entries {
+		node := root
+		parts := strings.Split(e.Path, "/")
+		for i, part := range parts {
+			last := i == len(parts)-1
+			node = node.child(part, !last || e.Dir)
+		}
+		if e.Dir {
+			dirs++
+		} else {
+			node.size = len(e.Content)
+			files++
+			total += len(e.Content)
+		}
+	}
+
+	fmt.Fprintf(w, "%s/\n", name)
+	printTreeChildren(w, root, "")
+	fmt.Fprintf(w, "\n%d directories, %d files, %s\n", dirs, files, formatSize(total))
+}
+
+func printTreeChildren(w io.Writer, n *treeNode, prefix string) {
+	children := n.sorted()
+	for i, c := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if c.dir {
+			fmt.Fprintf(w, "%s%s%s/\n", prefix, branch, c.name)
+			printTreeChildren(w, c, prefix+indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s%s (%s)\n", prefix, branch, c.name, formatSize(c.size))
+	}
+}
+
+// printContents prints every file in entries with a header line
+func printContents(w io.Writer, entries []generator.Entry) {
+	for _, e := range entries {
+		if e.Dir {
+			continue
+		}
+		fmt.Fprintf(w, "==> %s <==\n", e.Path)
+		w.Write(e.Content)
+		if len(e.Content) > 0 && e.Content[len(e.Content)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printDiff prints every file in entries as a unified diff against an
+// empty tree, suitable for pasting into a pull request
+func printDiff(w io.Writer, entries []generator.Entry) {
+	for _, e := range entries {
+		if e.Dir {
+			continue
+		}
+		fmt.Fprintf(w, "diff --git a/%s b/%s\n", e.Path, e.Path)
+		fmt.Fprintln(w, "new file mode 100644")
+		// Like git, empty files have no hunks and no file names
+		fmt.Fprint(w, diff.Unified("/dev/null", "b/"+e.Path, "", string(e.Content)))
+	}
+}
+
+// formatSize formats n bytes for humans
+func formatSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
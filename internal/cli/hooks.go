@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+	"github.com/azrakarakaya1/goscaffold/internal/userconfig"
+	"github.com/fatih/color"
+)
+
+// configHooks loads the hooks of the configuration files. Projects created
+// from a spec only run the hooks of their template.
+func configHooks() (*userconfig.Settings, error) {
+	if noHooks || fromSpec != "" {
+		return &userconfig.Settings{}, nil
+	}
+	return userconfig.Load(".")
+}
+
+// userHooks returns the hooks of the configuration files, the user
+// configuration's first
+func userHooks() (hooks.Hooks, error) {
+	settings, err := configHooks()
+	if err != nil {
+		return hooks.Hooks{}, err
+	}
+	return settings.Hooks.Append(settings.RepoHooks), nil
+}
+
+// hookOptions configures the hooks of goscaffold new. The hooks of a
+// .goscaffold.yaml come with the repository and need approval like those
+// of a template.
+func hookOptions() ([]generator.Option, error) {
+	if noHooks {
+		return []generator.Option{generator.WithoutHooks()}, nil
+	}
+	settings, err := configHooks()
+	if err != nil {
+		return nil, err
+	}
+	h := settings.Hooks
+	if !settings.RepoHooks.Empty() {
+		if err := confirmHooks(settings.RepoFile, settings.RepoFile, settings.RepoHooks); err != nil {
+			return nil, err
+		}
+		h = h.Append(settings.RepoHooks)
+	}
+	return []generator.Option{generator.WithHooks(h), generator.WithHookApproval(approveHooks)}, nil
+}
+
+// approveHooks shows the hooks of a template and asks whether to run them,
+// unless --trust-hooks was given
+func approveHooks(t generator.Template, h hooks.Hooks) error {
+	return confirmHooks("template "+t.Name(), fmt.Sprintf("Template %s (%s)", t.Name(), generator.TemplateSource(t)), h)
+}
+
+// confirmHooks shows the hooks h declared by what, described at length by
+// source, and asks whether to run them, unless --trust-hooks was given
+func confirmHooks(what, source string, h hooks.Hooks) error {
+	if trustHooks {
+		return nil
+	}
+	if noInteractive {
+		return fmt.Errorf("%s declares hooks; review them and pass --trust-hooks to run them or --no-hooks to skip them", what)
+	}
+
+	warn := color.New(color.FgYellow).SprintFunc()
+	fmt.Printf("  %s %s runs these commands:\n", warn("!"), source)
+	printHooks(h)
+	fmt.Println()
+
+	ok, err := promptForConfirm("Run these hooks")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("hooks of %s were not approved (use --no-hooks to generate without them)", what)
+	}
+	return nil
+}
+
+// printHooks lists h by stage
+func printHooks(h hooks.Hooks) {
+	for _, stage := range []hooks.Stage{hooks.Pre, hooks.Post} {
+		for _, hook := range h.Stage(stage) {
+			line := hook.Run
+			if hook.When != "" {
+				line += "  (when " + hook.When + ")"
+			}
+			fmt.Printf("    %-4s  %s\n", stage, line)
+		}
+	}
+}
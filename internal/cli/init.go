@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+// projectDir is the directory the project is written to when it is not
+// named after the project, as with goscaffold init
+var projectDir string
+
+// originModule is the module path derived from the origin remote of the
+// repository goscaffold init runs in
+var originModule string
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a Go project in the current directory",
+	Long: `Create a Go project in the current directory, e.g. a repository that was
+just cloned from your Git server.
+
+The project is named after the directory and, when the repository has an
+origin remote, the module path is derived from its URL. Otherwise init asks
+for the template and options like goscaffold new. Files that already exist
+are handled as with goscaffold new --into-existing: ignore files are merged
+and the others are prompted for, or kept with --no-interactive.
+
+Examples:
+  git clone git@git.example.com:team/orders.git && cd orders
+  goscaffold init -t api
+  goscaffold init -t cli --on-conflict backup --no-interactive`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	addProjectFlags(initCmd)
+	initCmd.Flags().StringArrayVar(&onConflict, "on-conflict", nil, "What to do with existing files: skip, overwrite, backup or prompt, or pattern=policy for some files (repeatable)")
+	addOutputFlags(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	projectDir = "."
+	intoExisting = true
+	originModule = remoteModulePath(dir)
+
+	// A spec names the project itself
+	if fromSpec != "" {
+		return runNew(cmd, nil)
+	}
+	name, err := projectNameFromDir(dir)
+	if err != nil {
+		return err
+	}
+	return runNew(cmd, []string{name})
+}
+
+// targetDir returns the directory the project is written to
+func targetDir() string {
+	if projectDir != "" {
+		return projectDir
+	}
+	return config.Name
+}
+
+// invalidNameChars matches what validateProjectName rejects after the
+// first letter
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// projectNameFromDir derives a project name from the base name of dir,
+// replacing characters such as dots with hyphens
+func projectNameFromDir(dir string) (string, error) {
+	name := invalidNameChars.ReplaceAllString(filepath.Base(dir), "-")
+	name = strings.Trim(name, "-")
+	if err := validateProjectName(name); err != nil {
+		return "", fmt.Errorf("cannot name the project after directory %s: %w (rename the directory or use goscaffold new)", filepath.Base(dir), err)
+	}
+	return name, nil
+}
+
+// remoteModulePath returns the module path matching the URL of the origin
+// remote of the repository in dir, or "" if there is none
+func remoteModulePath(dir string) string {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	p, ok := moduleFromRemote(strings.TrimSpace(string(out)))
+	if !ok {
+		return ""
+	}
+	return p
+}
+
+// scpRemote matches remotes in scp syntax, e.g. git@github.com:org/repo.git
+var scpRemote = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// moduleFromRemote turns a remote URL such as https://github.com/org/repo.git,
+// ssh://git@host:2222/org/repo.git or git@github.com:org/repo.git into the
+// module path host/org/repo
+func moduleFromRemote(remote string) (string, bool) {
+	var host, repo string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "file" {
+			return "", false
+		}
+		host, repo = u.Hostname(), u.Path
+	} else if m := scpRemote.FindStringSubmatch(remote); m != nil {
+		host, repo = m[1], m[2]
+	} else {
+		// A local path
+		return "", false
+	}
+
+	repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+	if host == "" || repo == "" {
+		return "", false
+	}
+	p := strings.ToLower(host) + "/" + repo
+	if generator.ValidateModulePath(p) != nil {
+		return "", false
+	}
+	return p, true
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"golang.org/x/mod/module"
+)
+
+// checkModulePath validates the module path of config and, with vanity,
+// points the vanity import page at the repository on the code host
+func checkModulePath(vanity bool) error {
+	if err := generator.ValidateModulePath(config.ModulePath); err != nil {
+		return err
+	}
+	if !vanity {
+		return nil
+	}
+
+	if config.GitHubUser == "" {
+		return fmt.Errorf("a vanity import page needs the owner of the repository (set it with --github)")
+	}
+	repo := generator.HostModulePath(config.Host, config.GitHubUser, config.Name)
+	root := config.ModulePath
+	if prefix, _, ok := module.SplitPathVersion(root); ok {
+		root = prefix
+	}
+	if root == repo {
+		return fmt.Errorf("module path %s is the repository path; a vanity import page needs a custom module path (set it with --module)", config.ModulePath)
+	}
+	config.VanityRepo = "https://" + repo
+	return nil
+}
@@ -2,11 +2,18 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+	"github.com/azrakarakaya1/goscaffold/internal/userconfig"
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
@@ -17,7 +24,10 @@ type ProjectConfig struct {
 	Name             string
 	ModulePath       string
 	Template         string
+	TemplateDir      string
 	GitHubUser       string
+	Host             string
+	ModulePrefix     string
 	IncludeMake      bool
 	IncludeDocker    bool
 	IncludeCI        bool
@@ -25,65 +35,396 @@ type ProjectConfig struct {
 	IncludePreCommit bool
 	IncludeTests     bool
 	InitGit          bool
+	Tidy             bool
+	License          string
+	Author           string
+	GoVersion        string
+	VanityRepo       string
+	Variables        map[string]string
+}
+
+// generatorConfig converts c into the configuration of the generator
+func (c ProjectConfig) generatorConfig() generator.Config {
+	return generator.Config{
+		Name:             c.Name,
+		ModulePath:       c.ModulePath,
+		Template:         c.Template,
+		TemplateDir:      c.TemplateDir,
+		IncludeMakefile:  c.IncludeMake,
+		IncludeDocker:    c.IncludeDocker,
+		IncludeCI:        c.IncludeCI,
+		IncludeLint:      c.IncludeLint,
+		IncludePreCommit: c.IncludePreCommit,
+		IncludeTests:     c.IncludeTests,
+		InitGit:          c.InitGit,
+		Tidy:             c.Tidy,
+		License:          c.License,
+		Author:           c.Author,
+		GoVersion:        c.GoVersion,
+		VanityRepo:       c.VanityRepo,
+		Variables:        c.Variables,
+	}
 }
 
 var config ProjectConfig
 var allDevOps bool
 var allQuality bool
 var noInteractive bool
+var dryRun bool
+var dryRunContents bool
+var dryRunDiff bool
+var outputArchive string
+var presetNames []string
+var fromSpec string
+var templateVars []string
+var noHooks bool
+var trustHooks bool
+var offline bool
+var proxyDir string
+var vanity bool
+var intoExisting bool
+var onConflict []string
 
 var newCmd = &cobra.Command{
 	Use:   "new [project-name]",
 	Short: "Create a new Go project",
-	Long: `Create a new Go project with the specified template and options.
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runNew,
+}
 
-Templates:
-  basic    - Minimal Go project (default)
-  cli      - CLI application with Cobra
-  api      - REST API with Chi router
-  grpc     - gRPC service with proto files
-  library  - Reusable Go library
+// defaultTemplate is used when --template is not given
+const defaultTemplate = "basic"
 
+const newExamples = `
 Examples:
   goscaffold new myapp
   goscaffold new myapi -t api -g username --all-devops
-  goscaffold new mycli -t cli -g username -D -Q`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runNew,
-}
+  goscaffold new mycli -t cli -g username -D -Q
+  goscaffold new mysvc --preset team-service
+  goscaffold new --from spec.yaml
+  goscaffold new mysvc --template-dir ./templates/service --var port=9090
+  goscaffold new mysvc --template-dir ./templates/service -g username
+  goscaffold new myapi -t api -D --dry-run --diff
+  goscaffold new myapi -t api -D --output-archive myapi.tar.gz`
 
 func init() {
 	rootCmd.AddCommand(newCmd)
 
+	newCmd.Long = newLongHelp()
+	addProjectFlags(newCmd)
+
+	// Existing directory flags
+	newCmd.Flags().BoolVar(&intoExisting, "into-existing", false, "Generate into the project directory even if it exists, e.g. a freshly cloned repository")
+	newCmd.Flags().StringArrayVar(&onConflict, "on-conflict", nil, "With --into-existing, what to do with existing files: skip, overwrite, backup or prompt, or pattern=policy for some files (repeatable)")
+
+	// Output flags
+	newCmd.Flags().StringVar(&outputArchive, "output-archive", "", "Write the project to a .tar.gz or .zip archive instead of a directory")
+	addOutputFlags(newCmd)
+}
+
+var registerOnce sync.Once
+var searchPathErrs []error
+
+// registerSearchPath makes templates from the search path available
+// alongside the built-ins, the first time it is called, and returns the
+// errors of the template directories that failed to load. Only commands
+// that pick templates by name call it, so other commands do not read the
+// search path.
+func registerSearchPath() []error {
+	registerOnce.Do(func() {
+		searchPathErrs = generator.RegisterSearchPath(generator.TemplateSearchPath())
+	})
+	return searchPathErrs
+}
+
+// addProjectFlags adds the flags describing the project, shared by
+// goscaffold new and goscaffold init
+func addProjectFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+
 	// Template flags
-	newCmd.Flags().StringVarP(&config.Template, "template", "t", "basic", "Project template (basic|cli|api|grpc|library)")
-	newCmd.Flags().StringVarP(&config.GitHubUser, "github", "g", "", "GitHub username for module path")
-	newCmd.Flags().StringVarP(&config.ModulePath, "module", "m", "", "Custom module path (overrides github)")
+	f.StringVarP(&config.Template, "template", "t", defaultTemplate, "Project template ("+strings.Join(templateNames(), "|")+")")
+	f.StringVar(&config.TemplateDir, "template-dir", "", "Path to a template directory containing "+generator.ManifestFile)
+	f.StringVarP(&config.GitHubUser, "github", "g", "", "User or organization on the code host for the module path")
+	f.StringVar(&config.Host, "host", generator.DefaultHost, "Code host for the module path, e.g. gitlab.com or a self-hosted server")
+	f.StringVarP(&config.ModulePath, "module", "m", "", "Custom module path (overrides github)")
+	f.BoolVar(&vanity, "vanity", false, "Generate a vanity import page pointing the custom module path at the repository on the code host")
 
 	// DevOps flags
-	newCmd.Flags().BoolVar(&config.IncludeMake, "makefile", false, "Include Makefile")
-	newCmd.Flags().BoolVar(&config.IncludeDocker, "docker", false, "Include Dockerfile and docker-compose")
-	newCmd.Flags().BoolVar(&config.IncludeCI, "ci", false, "Include GitHub Actions CI workflow")
-	newCmd.Flags().BoolVarP(&allDevOps, "all-devops", "D", false, "Include all DevOps files")
+	f.BoolVar(&config.IncludeMake, "makefile", false, "Include Makefile")
+	f.BoolVar(&config.IncludeDocker, "docker", false, "Include Dockerfile and docker-compose")
+	f.BoolVar(&config.IncludeCI, "ci", false, "Include GitHub Actions CI workflow")
+	f.BoolVarP(&allDevOps, "all-devops", "D", false, "Include all DevOps files")
 
 	// Quality flags
-	newCmd.Flags().BoolVar(&config.IncludeLint, "lint", false, "Include golangci-lint config")
-	newCmd.Flags().BoolVar(&config.IncludePreCommit, "precommit", false, "Include pre-commit hooks config")
-	newCmd.Flags().BoolVar(&config.IncludeTests, "tests", false, "Include test file scaffolding")
-	newCmd.Flags().BoolVarP(&allQuality, "all-quality", "Q", false, "Include all quality tools")
+	f.BoolVar(&config.IncludeLint, "lint", false, "Include golangci-lint config")
+	f.BoolVar(&config.IncludePreCommit, "precommit", false, "Include pre-commit hooks config")
+	f.BoolVar(&config.IncludeTests, "tests", false, "Include test file scaffolding")
+	f.BoolVarP(&allQuality, "all-quality", "Q", false, "Include all quality tools")
+
+	// Preset flags
+	f.StringSliceVar(&presetNames, "preset", nil, "Apply a named preset of template and options (repeatable, see goscaffold presets list)")
 
 	// Other flags
-	newCmd.Flags().BoolVar(&config.InitGit, "git", false, "Initialize git repository")
-	newCmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "Skip interactive prompts")
+	f.BoolVar(&config.InitGit, "git", false, "Initialize git repository")
+	f.BoolVar(&config.Tidy, "tidy", false, "Run go mod tidy in the generated project")
+	f.BoolVar(&offline, "offline", false, "With --tidy, resolve modules from the module cache only")
+	f.StringVar(&proxyDir, "proxy-dir", "", "With --tidy, resolve modules from this module proxy directory (implies --offline)")
+	f.StringVar(&config.License, "license", "", "License named in the README (default MIT)")
+	f.StringVar(&config.Author, "author", "", "Author named in the README")
+	f.StringVar(&config.GoVersion, "go-version", "", "Go version for go.mod, Docker and CI (default: the version of the go command)")
+	f.BoolVar(&noInteractive, "no-interactive", false, "Skip interactive prompts")
+	f.StringArrayVar(&templateVars, "var", nil, "Set a template variable (key=value, repeatable)")
+
+	// Hook flags
+	f.BoolVar(&noHooks, "no-hooks", false, "Do not run template or user hooks")
+	f.BoolVar(&trustHooks, "trust-hooks", false, "Run template and repository hooks without asking for confirmation")
+
+	// Dry-run flags
+	f.BoolVar(&dryRun, "dry-run", false, "Print the files that would be created without writing them")
+	f.BoolVar(&dryRunContents, "contents", false, "With --dry-run, also print the rendered file contents")
+	f.BoolVar(&dryRunDiff, "diff", false, "With --dry-run, print a unified diff against an empty tree")
+
+	// Spec flags
+	f.StringVar(&fromSpec, "from", "", "Create the project described by a spec file, or - for stdin (see goscaffold schema)")
 }
 
 func runNew(cmd *cobra.Command, args []string) error {
-	success := color.New(color.FgGreen).SprintFunc()
-	info := color.New(color.FgCyan).SprintFunc()
-	warn := color.New(color.FgYellow).SprintFunc()
+	// With --output json, stdout carries the result document and the
+	// events go to stderr
+	events := io.Writer(os.Stdout)
+	if outputFormat == outputJSON {
+		events = os.Stderr
+		// Prompts would corrupt the JSON on stdout
+		noInteractive = true
+		cmd.SilenceUsage = true
+	}
+	r, err := newReporter(events)
+	if err != nil {
+		return err
+	}
+	r.Banner()
+	reportWarnings(r, registerSearchPath())
 
-	fmt.Println()
-	fmt.Printf("  %s\n\n", info("goscaffold - Go Project Generator"))
+	// Build the configuration from a spec or from flags, defaults and prompts
+	var plan newPlan
+	if fromSpec != "" {
+		plan, err = configFromSpec(cmd, args)
+	} else {
+		plan, err = configFromFlags(cmd, args)
+	}
+	if err != nil {
+		return err
+	}
+	tmpl := plan.template
+
+	// Target the installed Go unless a version was chosen
+	if config.GoVersion == "" {
+		config.GoVersion = generator.DetectGoVersion()
+	} else if config.GoVersion, err = generator.ParseGoVersion(config.GoVersion); err != nil {
+		return err
+	}
+
+	// Resolve template variables, prompting for those not set with --var
+	var prompt generator.VariablePrompt
+	if !noInteractive {
+		prompt = promptForVariable
+	}
+	if config.Variables, err = generator.ResolveVariables(tmpl, config.generatorConfig(), prompt); err != nil {
+		return err
+	}
+
+	// Display configuration
+	r.Field("Project", config.Name)
+	r.Field("Module", config.ModulePath)
+	r.Field("Template", config.Template)
+	r.Field("Go", config.GoVersion)
+	if len(plan.presets) > 0 {
+		r.Field("Presets", strings.Join(plan.presets, ", "))
+	}
+	if len(plan.defaultFiles) > 0 {
+		r.Field("Defaults", strings.Join(plan.defaultFiles, ", "))
+	}
+
+	// Generate the project
+	genConfig := config.generatorConfig()
+
+	if dryRun {
+		opts := []generator.Option{generator.WithOutput(generator.NewMemFS()), generator.WithToolVersion(versionStr)}
+		if outputFormat == outputJSON {
+			// The events list the files that would be written
+			opts = append(opts, generator.WithReporter(r))
+		}
+		gen := generator.New(genConfig, opts...)
+		if err := gen.Generate(); err != nil {
+			return fmt.Errorf("failed to generate project: %w", err)
+		}
+		if outputFormat == outputJSON {
+			res := newResultFor(genConfig, r, gen.Entries(), nil)
+			res.DryRun = true
+			return res.write(targetDir())
+		}
+		h, err := userHooks()
+		if err != nil {
+			return err
+		}
+		if !noHooks {
+			h = hooks.Around(h, generator.TemplateHooks(tmpl))
+		}
+		printDryRun(config.Name, gen.Entries(), config.InitGit, config.Tidy, h)
+		return nil
+	}
+
+	if outputArchive != "" {
+		entries, err := generateArchive(genConfig, outputArchive, r)
+		if err != nil {
+			return err
+		}
+		r.Success(fmt.Sprintf("Project '%s' written to %s", config.Name, outputArchive))
+		if outputFormat == outputJSON {
+			res := newResultFor(genConfig, r, entries, nil)
+			if res.Archive, err = filepath.Abs(outputArchive); err != nil {
+				return err
+			}
+			return res.write("")
+		}
+		return nil
+	}
+
+	opts, err := hookOptions()
+	if err != nil {
+		return err
+	}
+	if intoExisting {
+		conflicts, err := conflictOptions()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, generator.WithConflicts(conflicts))
+	}
+	if offline || proxyDir != "" {
+		opts = append(opts, generator.WithOffline(proxyDir))
+	}
+	opts = append(opts, generator.WithOutput(generator.NewOSFS(targetDir())), generator.WithToolVersion(versionStr), generator.WithReporter(r))
+	gen := generator.New(genConfig, opts...)
+	if err := gen.Generate(); err != nil {
+		return fmt.Errorf("failed to generate project: %w", err)
+	}
+
+	// Success message
+	r.Success(fmt.Sprintf("Project '%s' created successfully!", config.Name))
+	r.Conflicts(gen.Conflicts())
+	var next []string
+	if projectDir == "" {
+		next = append(next, "cd "+config.Name)
+	}
+	if !config.Tidy {
+		next = append(next, "go mod tidy")
+	}
+	next = append(next, tmpl.RunCommand(genConfig))
+	r.NextSteps(next)
+
+	if outputFormat == outputJSON {
+		res := newResultFor(genConfig, r, gen.Entries(), next)
+		res.Conflicts = gen.Conflicts()
+		return res.write(targetDir())
+	}
+	return nil
+}
+
+// newResult is the JSON form of the goscaffold new output
+type newResult struct {
+	Config     generator.Config `json:"config"`
+	ModulePath string           `json:"modulePath"`
+	// Dir is the absolute project directory, or where it would be for a
+	// dry run; Archive is set instead for --output-archive
+	Dir       string       `json:"dir,omitempty"`
+	Archive   string       `json:"archive,omitempty"`
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Files     []resultFile `json:"files"`
+	NextSteps []string     `json:"nextSteps"`
+	Warnings  []string     `json:"warnings"`
+	// Conflicts lists the existing files met with --into-existing
+	Conflicts []generator.Conflict `json:"conflicts,omitempty"`
+}
+
+// resultFile is a file of the generated project
+type resultFile struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	Size int    `json:"size"`
+}
+
+// newResultFor describes the project generated for cfg from its entries
+// and the warnings collected by r
+func newResultFor(cfg generator.Config, r reporter, entries []generator.Entry, next []string) *newResult {
+	res := &newResult{
+		Config:     cfg,
+		ModulePath: cfg.ModulePath,
+		Files:      []resultFile{},
+		NextSteps:  append([]string{}, next...),
+		Warnings:   []string{},
+	}
+	for _, e := range entries {
+		if !e.Dir {
+			res.Files = append(res.Files, resultFile{Path: e.Path, Hash: generator.HashContent(e.Content), Size: len(e.Content)})
+		}
+	}
+	if jr, ok := r.(*jsonReporter); ok {
+		res.Warnings = append(res.Warnings, jr.warnings...)
+	}
+	return res
+}
+
+// write prints res to stdout, setting Dir to the absolute path of dir
+// unless it is empty
+func (res *newResult) write(dir string) error {
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		res.Dir = abs
+	}
+	return writeJSON(os.Stdout, res)
+}
+
+// newPlan is the outcome of building the configuration of goscaffold new
+type newPlan struct {
+	template generator.Template
+	// presets and defaultFiles are shown with the configuration
+	presets      []string
+	defaultFiles []string
+}
+
+// configFromFlags fills config from the user defaults, presets, flags and,
+// unless disabled, interactive prompts
+func configFromFlags(cmd *cobra.Command, args []string) (newPlan, error) {
+	var plan newPlan
+
+	// Load user defaults and apply presets on top; flags given on the
+	// command line win
+	settings, err := userconfig.Load(".")
+	if err != nil {
+		return plan, err
+	}
+	presets, err := availablePresets(settings, config.TemplateDir)
+	if err != nil {
+		return plan, err
+	}
+
+	selected := presetNames
+	if allQuality {
+		selected = append([]string{"quality"}, selected...)
+	}
+	if allDevOps {
+		selected = append([]string{"devops"}, selected...)
+	}
+	defaults, err := applyPresets(settings.Defaults, presets, selected)
+	if err != nil {
+		return plan, err
+	}
+	applyDefaults(cmd, defaults)
 
 	// Get project name
 	if len(args) > 0 {
@@ -91,67 +432,75 @@ func runNew(cmd *cobra.Command, args []string) error {
 	} else if !noInteractive {
 		name, err := promptForInput("Project name", "myproject")
 		if err != nil {
-			return err
+			return plan, err
 		}
 		config.Name = name
 	} else {
-		return fmt.Errorf("project name is required")
+		return plan, fmt.Errorf("project name is required")
 	}
 
-	// Validate project name
-	if err := validateProjectName(config.Name); err != nil {
-		return err
+	if err := checkProjectName(config.Name); err != nil {
+		return plan, err
 	}
 
-	// Check if directory exists
-	if _, err := os.Stat(config.Name); !os.IsNotExist(err) {
-		return fmt.Errorf("directory '%s' already exists", config.Name)
+	if config.Variables, err = parseVars(templateVars); err != nil {
+		return plan, err
 	}
 
-	// Get GitHub username if not provided
-	if config.ModulePath == "" && config.GitHubUser == "" && !noInteractive {
-		username, err := promptForInput("GitHub username", "")
+	// Select a template or preset if not provided and interactive
+	if !cmd.Flags().Changed("template") && defaults.Template == "" && config.TemplateDir == "" && !noInteractive {
+		template, preset, err := promptForTemplate(presets)
 		if err != nil {
-			return err
+			return plan, err
+		}
+		if preset != nil {
+			selected = append(selected, preset.Name)
+			defaults.Merge(preset.Defaults)
+			applyDefaults(cmd, defaults)
+		} else {
+			config.Template = template
+		}
+	}
+
+	// In a clone, the remote names the module unless flags say otherwise
+	if originModule != "" && !cmd.Flags().Changed("module") && !cmd.Flags().Changed("github") {
+		config.ModulePath = originModule
+	}
+
+	// Get the repository owner if not provided
+	if config.ModulePath == "" && config.ModulePrefix == "" && config.GitHubUser == "" && !noInteractive {
+		username, err := promptForInput("Username or organization on "+config.Host, "")
+		if err != nil {
+			return plan, err
 		}
 		config.GitHubUser = username
 	}
 
 	// Build module path
 	if config.ModulePath == "" {
-		if config.GitHubUser != "" {
-			config.ModulePath = fmt.Sprintf("github.com/%s/%s", config.GitHubUser, config.Name)
-		} else {
+		switch {
+		case config.ModulePrefix != "":
+			config.ModulePath = path.Join(config.ModulePrefix, config.Name)
+		case config.GitHubUser != "":
+			config.ModulePath = generator.HostModulePath(config.Host, config.GitHubUser, config.Name)
+		default:
 			config.ModulePath = config.Name
 		}
 	}
-
-	// Select template if not provided and interactive
-	if !cmd.Flags().Changed("template") && !noInteractive {
-		template, err := promptForTemplate()
-		if err != nil {
-			return err
-		}
-		config.Template = template
+	if err := checkModulePath(vanity); err != nil {
+		return plan, err
 	}
 
-	// Apply aggregate flags
-	if allDevOps {
-		config.IncludeMake = true
-		config.IncludeDocker = true
-		config.IncludeCI = true
-	}
-	if allQuality {
-		config.IncludeLint = true
-		config.IncludePreCommit = true
-		config.IncludeTests = true
+	// Resolve the template up front so errors surface before generation
+	if plan.template, err = resolveNewTemplate(); err != nil {
+		return plan, err
 	}
 
 	// Ask about DevOps if not specified and interactive
-	if !noInteractive && !allDevOps && !cmd.Flags().Changed("makefile") && !cmd.Flags().Changed("docker") && !cmd.Flags().Changed("ci") {
+	if !noInteractive && !devOpsConfigured(cmd, defaults) {
 		includeDevOps, err := promptForConfirm("Include DevOps files (Makefile, Docker, CI)?")
 		if err != nil {
-			return err
+			return plan, err
 		}
 		if includeDevOps {
 			config.IncludeMake = true
@@ -161,10 +510,10 @@ func runNew(cmd *cobra.Command, args []string) error {
 	}
 
 	// Ask about quality tools if not specified and interactive
-	if !noInteractive && !allQuality && !cmd.Flags().Changed("lint") && !cmd.Flags().Changed("precommit") && !cmd.Flags().Changed("tests") {
+	if !noInteractive && !qualityConfigured(cmd, defaults) {
 		includeQuality, err := promptForConfirm("Include code quality tools (linter, pre-commit, tests)?")
 		if err != nil {
-			return err
+			return plan, err
 		}
 		if includeQuality {
 			config.IncludeLint = true
@@ -173,47 +522,137 @@ func runNew(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Display configuration
-	fmt.Printf("  %s %s\n", info("Project:"), config.Name)
-	fmt.Printf("  %s %s\n", info("Module:"), config.ModulePath)
-	fmt.Printf("  %s %s\n", info("Template:"), config.Template)
-	fmt.Println()
+	plan.presets = selected
+	plan.defaultFiles = settings.Files
+	return plan, nil
+}
 
-	// Generate the project
-	gen := generator.New(generator.Config{
-		Name:             config.Name,
-		ModulePath:       config.ModulePath,
-		Template:         config.Template,
-		IncludeMakefile:  config.IncludeMake,
-		IncludeDocker:    config.IncludeDocker,
-		IncludeCI:        config.IncludeCI,
-		IncludeLint:      config.IncludeLint,
-		IncludePreCommit: config.IncludePreCommit,
-		IncludeTests:     config.IncludeTests,
-		InitGit:          config.InitGit,
-	})
+// generateArchive generates the project into the archive file name,
+// reporting progress to r, and returns the entries written. The archive is
+// written to a temporary file next to name, which only replaces it once
+// complete; an existing file is never overwritten.
+func generateArchive(cfg generator.Config, name string, r generator.Reporter) (entries []generator.Entry, err error) {
+	if err := checkArchive(name); err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err == nil {
+			// CreateTemp makes the file private
+			err = f.Chmod(0644)
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(f.Name(), name)
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	archive, err := generator.NewArchiveFS(name, f)
+	if err != nil {
+		return nil, err
+	}
 
+	// Keep the project directory as the top-level entry of the archive
+	out := generator.SubFS(archive, cfg.Name)
+	gen := generator.New(cfg, generator.WithOutput(out), generator.WithToolVersion(versionStr), generator.WithReporter(r))
 	if err := gen.Generate(); err != nil {
-		return fmt.Errorf("failed to generate project: %w", err)
+		return nil, fmt.Errorf("failed to generate project: %w", err)
 	}
+	return gen.Entries(), archive.Close()
+}
 
-	// Success message
-	fmt.Printf("  %s Project '%s' created successfully!\n\n", success("✓"), config.Name)
-	fmt.Printf("  %s\n", warn("Next steps:"))
-	fmt.Printf("    cd %s\n", config.Name)
-	fmt.Printf("    go mod tidy\n")
-	if config.Template == "api" || config.Template == "grpc" {
-		fmt.Printf("    go run ./cmd/%s\n", config.Name)
-	} else if config.Template == "cli" {
-		fmt.Printf("    go run ./cmd/%s\n", config.Name)
-	} else {
-		fmt.Printf("    go run .\n")
+// checkArchive makes sure an archive can be written to name without
+// replacing an existing file
+func checkArchive(name string) error {
+	if err := generator.CheckArchiveName(name); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(name); err == nil {
+		return fmt.Errorf("'%s' already exists", name)
+	}
+	return nil
+}
+
+// printDryRun prints what a dry run would have created
+func printDryRun(name string, entries []generator.Entry, initGit, tidy bool, h hooks.Hooks) {
+	warn := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Printf("  %s\n\n", warn("Dry run: nothing was written to disk"))
+	switch {
+	case dryRunDiff:
+		printDiff(os.Stdout, entries)
+	case dryRunContents:
+		printTree(os.Stdout, name, entries)
+		fmt.Println()
+		printContents(os.Stdout, entries)
+	default:
+		printTree(os.Stdout, name, entries)
+	}
+	if initGit {
+		fmt.Println("\ngit init would be run in the project directory")
+	}
+	if tidy {
+		fmt.Println("\ngo mod tidy would be run in the project directory")
+	}
+	if !h.Empty() {
+		fmt.Println("\nThese hooks would be run in the project directory:")
+		printHooks(h)
 	}
 	fmt.Println()
+}
 
+// checkProjectName validates name and makes sure the project directory, or
+// archive, does not exist yet when it is going to be written, unless the
+// project is generated into it
+func checkProjectName(name string) error {
+	if err := validateProjectName(name); err != nil {
+		return err
+	}
+
+	if intoExisting && outputArchive != "" {
+		return fmt.Errorf("--into-existing cannot be combined with --output-archive")
+	}
+	if len(onConflict) > 0 && !intoExisting {
+		return fmt.Errorf("--on-conflict needs --into-existing")
+	}
+	if outputArchive != "" && !dryRun {
+		if err := checkArchive(outputArchive); err != nil {
+			return err
+		}
+	}
+
+	writesDir := !dryRun && outputArchive == ""
+	info, err := os.Stat(targetDir())
+	switch {
+	case !writesDir || os.IsNotExist(err):
+	case intoExisting && err == nil && info.IsDir():
+	case intoExisting && err == nil:
+		return fmt.Errorf("'%s' exists and is not a directory", targetDir())
+	default:
+		return fmt.Errorf("directory '%s' already exists (use --into-existing to generate into it)", targetDir())
+	}
 	return nil
 }
 
+// resolveNewTemplate resolves the configured template and records its name
+func resolveNewTemplate() (generator.Template, error) {
+	tmpl, err := generator.ResolveTemplate(config.Template, config.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	config.Template = tmpl.Name()
+	return tmpl, nil
+}
+
 func validateProjectName(name string) error {
 	if name == "" {
 		return fmt.Errorf("project name cannot be empty")
@@ -244,6 +683,57 @@ func promptForInput(label, defaultVal string) (string, error) {
 	return prompt.Run()
 }
 
+// promptForVariable asks for a template variable with the widget that
+// suits its type
+func promptForVariable(v generator.Variable, def string) (string, error) {
+	label := v.Name
+	if v.Help != "" {
+		label = fmt.Sprintf("%s (%s)", v.Help, v.Name)
+	}
+
+	switch v.Type {
+	case generator.VarBool:
+		items := []string{"yes", "no"}
+		pos := 1
+		if b, _ := strconv.ParseBool(def); b {
+			pos = 0
+		}
+		prompt := promptui.Select{Label: label, Items: items, CursorPos: pos}
+		idx, _, err := prompt.Run()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(idx == 0), nil
+
+	case generator.VarEnum:
+		pos := 0
+		for i, o := range v.Options {
+			if o == def {
+				pos = i
+			}
+		}
+		prompt := promptui.Select{Label: label, Items: v.Options, CursorPos: pos}
+		_, value, err := prompt.Run()
+		return value, err
+
+	default:
+		if v.Type == generator.VarList {
+			label += " (comma-separated)"
+		}
+		prompt := promptui.Prompt{
+			Label:   label,
+			Default: def,
+			Validate: func(s string) error {
+				if s == "" && v.Type != generator.VarList {
+					return fmt.Errorf("a value is required")
+				}
+				return v.Validate(s)
+			},
+		}
+		return prompt.Run()
+	}
+}
+
 func promptForConfirm(label string) (bool, error) {
 	prompt := promptui.Prompt{
 		Label:     label,
@@ -259,32 +749,67 @@ func promptForConfirm(label string) (bool, error) {
 	return strings.ToLower(result) == "y" || result == "", nil
 }
 
-func promptForTemplate() (string, error) {
-	templates := []struct {
-		Name        string
-		Description string
-	}{
-		{"basic", "Minimal Go project"},
-		{"cli", "CLI application with Cobra"},
-		{"api", "REST API with Chi router"},
-		{"grpc", "gRPC service"},
-		{"library", "Reusable Go library"},
+// promptForTemplate lets the user pick a template or one of the presets
+// that names a template. It returns the template name, or the preset when
+// one was picked.
+func promptForTemplate(presets []userconfig.Preset) (string, *userconfig.Preset, error) {
+	templates := generator.Templates()
+
+	var templateItems []string
+	for _, t := range templates {
+		templateItems = append(templateItems, fmt.Sprintf("%s - %s", t.Name(), t.Description()))
 	}
 
-	templateItems := make([]string, len(templates))
-	for i, t := range templates {
-		templateItems[i] = fmt.Sprintf("%s - %s", t.Name, t.Description)
+	var choices []userconfig.Preset
+	for _, p := range presets {
+		if p.Template == "" && p.TemplateDir == "" {
+			continue
+		}
+		choices = append(choices, p)
+		templateItems = append(templateItems, fmt.Sprintf("%s (preset) - %s", p.Name, p.Description))
 	}
 
 	prompt := promptui.Select{
-		Label: "Select project template",
+		Label: "Select project template or preset",
 		Items: templateItems,
 	}
 
 	idx, _, err := prompt.Run()
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	if idx >= len(templates) {
+		return "", &choices[idx-len(templates)], nil
 	}
+	return templates[idx].Name(), nil, nil
+}
+
+// templateNames returns the names of all registered templates
+func templateNames() []string {
+	var names []string
+	for _, t := range generator.Templates() {
+		names = append(names, t.Name())
+	}
+	return names
+}
 
-	return templates[idx].Name, nil
+// newLongHelp builds the help text of the new command from the registry
+func newLongHelp() string {
+	var b strings.Builder
+	b.WriteString("Create a new Go project with the specified template and options.\n\nTemplates:\n")
+	for _, t := range generator.Templates() {
+		line := fmt.Sprintf("  %-8s - %s", t.Name(), t.Description())
+		if t.Name() == defaultTemplate {
+			line += " (default)"
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(`
+Other names are looked up as template directories in $GOSCAFFOLD_TEMPLATE_PATH
+and the goscaffold/templates folder of your user config directory.
+Run goscaffold templates list to see every template with its source.
+`)
+	b.WriteString(newExamples)
+	return b.String()
 }
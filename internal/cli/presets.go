@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/azrakarakaya1/goscaffold/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+var presetsTemplateDir string
+
+var presetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "Manage named presets of template and options",
+	Long: `Presets bundle a template with options such as DevOps files, quality
+tools and a module prefix, and are applied with goscaffold new --preset.
+
+Presets are defined under the presets key of the goscaffold configuration
+files or of a template directory's template.yaml:
+
+  presets:
+    team-service:
+      description: Team HTTP service
+      template: api
+      modulePrefix: go.example.com/team
+      makefile: true
+      docker: true
+      ci: true
+      lint: true
+      precommit: true
+      git: true
+
+The built-in devops and quality presets back --all-devops and --all-quality.`,
+}
+
+var presetsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available presets",
+	Args:  cobra.NoArgs,
+	RunE:  runPresetsList,
+}
+
+func init() {
+	rootCmd.AddCommand(presetsCmd)
+	presetsCmd.AddCommand(presetsListCmd)
+
+	presetsListCmd.Flags().StringVar(&presetsTemplateDir, "template-dir", "", "Also list the presets of this template directory")
+}
+
+func runPresetsList(cmd *cobra.Command, args []string) error {
+	for _, err := range registerSearchPath() {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	settings, err := userconfig.Load(".")
+	if err != nil {
+		return err
+	}
+	presets, err := availablePresets(settings, presetsTemplateDir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDESCRIPTION\tSETTINGS\tSOURCE")
+	for _, p := range presets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, p.Description, p.Summary(), p.Source)
+	}
+	return w.Flush()
+}
+
+// availablePresets returns every preset by name: the built-in ones, those
+// of registered template directories and of templateDir, and those of the
+// configuration files, in increasing order of precedence. The search path
+// must be registered first.
+func availablePresets(settings *userconfig.Settings, templateDir string) ([]userconfig.Preset, error) {
+	byName := make(map[string]userconfig.Preset)
+	add := func(presets ...userconfig.Preset) {
+		for _, p := range presets {
+			byName[p.Name] = p
+		}
+	}
+
+	add(userconfig.BuiltinPresets()...)
+	for _, t := range generator.Templates() {
+		if dt, ok := t.(*generator.DirTemplate); ok {
+			add(dt.Presets()...)
+		}
+	}
+	for _, dir := range []string{settings.TemplateDir, templateDir} {
+		if dir == "" {
+			continue
+		}
+		dt, err := generator.LoadDirTemplate(dir)
+		if err != nil {
+			return nil, err
+		}
+		add(dt.Presets()...)
+	}
+	for _, p := range settings.Presets {
+		add(p)
+	}
+
+	presets := make([]userconfig.Preset, 0, len(byName))
+	for _, p := range byName {
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// applyPresets merges the presets called names into defaults, in order
+func applyPresets(defaults userconfig.Defaults, presets []userconfig.Preset, names []string) (userconfig.Defaults, error) {
+	for _, name := range names {
+		p, err := lookupPreset(presets, name)
+		if err != nil {
+			return defaults, err
+		}
+		defaults.Merge(p.Defaults)
+	}
+	return defaults, nil
+}
+
+// lookupPreset finds the preset called name
+func lookupPreset(presets []userconfig.Preset, name string) (userconfig.Preset, error) {
+	for _, p := range presets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return userconfig.Preset{}, fmt.Errorf("unknown preset '%s' (see goscaffold presets list)", name)
+}
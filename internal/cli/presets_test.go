@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/azrakarakaya1/goscaffold/internal/userconfig"
+)
+
+// writeTemplate creates the template directory dir holding manifest and an
+// empty main.go
+func writeTemplate(t *testing.T, dir, manifest string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "files"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, generator.ManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "files", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestAvailablePresets(t *testing.T) {
+	root := t.TempDir()
+	searchPath := filepath.Join(root, "templates")
+	writeTemplate(t, filepath.Join(searchPath, "presets-team"), `name: presets-team
+presets:
+  svc:
+    description: registered service
+  web:
+    description: registered web
+    template: api
+  cli:
+    description: registered cli
+    template: cli
+`)
+	if errs := generator.RegisterSearchPath([]string{searchPath}); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	configured := writeTemplate(t, filepath.Join(root, "configured"), `name: configured
+presets:
+  web:
+    description: configured web
+`)
+	given := writeTemplate(t, filepath.Join(root, "given"), `name: given
+presets:
+  cli:
+    description: given cli
+`)
+
+	settings := &userconfig.Settings{
+		Defaults: userconfig.Defaults{TemplateDir: configured},
+		Presets: map[string]userconfig.Preset{
+			"svc": {Name: "svc", Description: "user service", Source: "config.yaml"},
+		},
+	}
+	presets, err := availablePresets(settings, given)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Configuration files win over template directories, the given
+	// template directory over the configured one and both over the
+	// registered templates
+	want := map[string]string{
+		"cli":     "given cli",
+		"devops":  "Makefile, Docker and CI workflow",
+		"quality": "Linter, pre-commit hooks and tests",
+		"svc":     "user service",
+		"web":     "configured web",
+	}
+	var names []string
+	for _, p := range presets {
+		names = append(names, p.Name)
+		if p.Description != want[p.Name] {
+			t.Errorf("preset %s = %q, want %q", p.Name, p.Description, want[p.Name])
+		}
+	}
+	if got := strings.Join(names, ","); got != "cli,devops,quality,svc,web" {
+		t.Errorf("presets = %s, want them sorted by name", got)
+	}
+
+	// Presets of a template without a template setting use that template
+	for _, p := range presets {
+		if p.Name == "web" && p.TemplateDir != configured {
+			t.Errorf("web uses %q, want %s", p.TemplateDir, configured)
+		}
+	}
+}
+
+func TestApplyPresets(t *testing.T) {
+	yes, no := true, false
+	presets := append(userconfig.BuiltinPresets(), userconfig.Preset{
+		Name:     "team",
+		Defaults: userconfig.Defaults{Template: "api", ModulePrefix: "go.example.com/team", Docker: &no},
+	})
+	defaults := userconfig.Defaults{GitHub: "acme", Template: "cli", Git: &yes}
+
+	got, err := applyPresets(defaults, presets, []string{"devops", "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Later presets win; settings no preset touches are kept
+	if got.Template != "api" || got.ModulePrefix != "go.example.com/team" || got.GitHub != "acme" {
+		t.Errorf("template, prefix, github = %q, %q, %q", got.Template, got.ModulePrefix, got.GitHub)
+	}
+	for name, v := range map[string]*bool{"makefile": got.Makefile, "ci": got.CI, "git": got.Git} {
+		if v == nil || !*v {
+			t.Errorf("%s = %v, want true", name, v)
+		}
+	}
+	if got.Docker == nil || *got.Docker {
+		t.Errorf("docker = %v, want the team preset to turn it off", got.Docker)
+	}
+
+	if _, err := applyPresets(defaults, presets, []string{"devops", "nope"}); err == nil || !strings.Contains(err.Error(), "unknown preset 'nope'") {
+		t.Errorf("applyPresets with an unknown preset = %v, want an error", err)
+	}
+}
+
+func TestBuiltinPresetsCannotBeRedefined(t *testing.T) {
+	root := t.TempDir()
+
+	// In the template given with --template-dir
+	dir := writeTemplate(t, filepath.Join(root, "given"), "name: given\npresets:\n  quality:\n    lint: false\n")
+	if _, err := availablePresets(&userconfig.Settings{}, dir); err == nil || !strings.Contains(err.Error(), "preset quality is built in") {
+		t.Errorf("availablePresets = %v, want the quality preset refused", err)
+	}
+
+	// In a template on the search path, which is then not registered
+	searchPath := filepath.Join(root, "templates")
+	writeTemplate(t, filepath.Join(searchPath, "presets-devops"), "name: presets-devops\npresets:\n  devops:\n    docker: false\n")
+	if errs := generator.RegisterSearchPath([]string{searchPath}); len(errs) != 1 || !strings.Contains(errs[0].Error(), "preset devops is built in") {
+		t.Errorf("RegisterSearchPath = %v, want the devops preset refused", errs)
+	}
+	presets, err := availablePresets(&userconfig.Settings{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := lookupPreset(presets, "devops"); p.Source != userconfig.BuiltinSource || p.Docker == nil || !*p.Docker {
+		t.Errorf("devops = %+v, want the built-in preset", p)
+	}
+
+	// In a configuration file
+	repo := filepath.Join(root, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, userconfig.FileName), []byte("presets:\n  devops:\n    ci: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	if _, err := userconfig.Load(repo); err == nil || !strings.Contains(err.Error(), "preset devops is built in") {
+		t.Errorf("Load = %v, want the devops preset refused", err)
+	}
+}
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of --output
+const (
+	outputText  = "text"
+	outputPlain = "plain"
+	outputJSON  = "json"
+)
+
+var outputFormat string
+var quietOutput bool
+var verboseOutput bool
+
+// addOutputFlags adds the flags that choose how cmd reports its progress
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text, plain (no colors) or json")
+	cmd.Flags().BoolVarP(&quietOutput, "quiet", "q", false, "Only print warnings")
+	cmd.Flags().BoolVarP(&verboseOutput, "verbose", "v", false, "Also print every file written and the duration of each step")
+	cmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+}
+
+// reporter presents the progress and outcome of a command: the events of
+// the generator and the messages of the command itself
+type reporter interface {
+	generator.Reporter
+	// Banner introduces the command
+	Banner()
+	// Field shows a setting the command resolved, e.g. the module path
+	Field(name, value string)
+	// Success announces the outcome of the command
+	Success(msg string)
+	// NextSteps lists commands for the user to run
+	NextSteps(cmds []string)
+	// Conflicts sums up how existing files were treated
+	Conflicts(cs []generator.Conflict)
+	// Upgraded shows what goscaffold upgrade did with a file
+	Upgraded(u generator.FileUpgrade)
+	// Drifted shows how a file differs from the template output
+	Drifted(d generator.FileDrift)
+}
+
+// newReporter returns the reporter chosen by the output flags. Messages
+// are written to events, as JSON lines with --output json; results such
+// as diffs always go to stdout.
+func newReporter(events io.Writer) (reporter, error) {
+	switch outputFormat {
+	case outputJSON:
+		if quietOutput || verboseOutput {
+			return nil, fmt.Errorf("--quiet and --verbose cannot be combined with --output json")
+		}
+		return &jsonReporter{enc: json.NewEncoder(events)}, nil
+	case outputText, outputPlain:
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want text, plain or json)", outputFormat)
+	}
+
+	if quietOutput {
+		return quietReporter{w: os.Stderr}, nil
+	}
+	return newTextReporter(events, outputFormat == outputPlain, verboseOutput), nil
+}
+
+// reportWarnings reports each of errs as a warning through r
+func reportWarnings(r generator.Reporter, errs []error) {
+	for _, err := range errs {
+		r.Report(generator.Event{Kind: generator.EventWarning, Message: err.Error()})
+	}
+}
+
+// textReporter prints progress for people, in color unless plain
+type textReporter struct {
+	w       io.Writer
+	out     io.Writer
+	verbose bool
+
+	info, success, warn, fail func(a ...interface{}) string
+	arrow, check, cross, dot  string
+
+	// inFields is set while settings are printed, which are followed by
+	// a blank line
+	inFields bool
+}
+
+func newTextReporter(w io.Writer, plain, verbose bool) *textReporter {
+	r := &textReporter{
+		w:       w,
+		out:     os.Stdout,
+		verbose: verbose,
+		info:    color.New(color.FgCyan).SprintFunc(),
+		success: color.New(color.FgGreen).SprintFunc(),
+		warn:    color.New(color.FgYellow).SprintFunc(),
+		fail:    color.New(color.FgRed).SprintFunc(),
+		arrow:   "→",
+		check:   "✓",
+		cross:   "✗",
+		dot:     "•",
+	}
+	if plain {
+		r.info, r.success, r.warn, r.fail = fmt.Sprint, fmt.Sprint, fmt.Sprint, fmt.Sprint
+		r.arrow, r.check, r.cross, r.dot = "->", "OK", "FAIL", "-"
+	}
+	return r
+}
+
+func (r *textReporter) Banner() {
+	fmt.Fprintln(r.w)
+	fmt.Fprintf(r.w, "  %s\n\n", r.info("goscaffold - Go Project Generator"))
+}
+
+func (r *textReporter) Field(name, value string) {
+	r.inFields = true
+	fmt.Fprintf(r.w, "  %s %s\n", r.info(name+":"), value)
+}
+
+// endFields separates the settings from what follows
+func (r *textReporter) endFields() {
+	if r.inFields {
+		fmt.Fprintln(r.w)
+		r.inFields = false
+	}
+}
+
+func (r *textReporter) Report(e generator.Event) {
+	r.endFields()
+	switch e.Kind {
+	case generator.EventStepStarted:
+		fmt.Fprintf(r.w, "  %s %s\n", r.info(r.arrow), e.Message)
+	case generator.EventStepFinished:
+		if r.verbose && e.Err == nil {
+			fmt.Fprintf(r.w, "    done in %s\n", e.Duration.Round(time.Microsecond))
+		}
+	case generator.EventFileWritten:
+		if r.verbose && e.Dir {
+			fmt.Fprintf(r.w, "    + %s/\n", e.Path)
+		} else if r.verbose {
+			fmt.Fprintf(r.w, "    + %s (%d bytes)\n", e.Path, e.Size)
+		}
+	case generator.EventHookOutput:
+		fmt.Fprintf(r.w, "    %s\n", e.Message)
+	case generator.EventWarning:
+		fmt.Fprintf(r.w, "  %s %s\n", r.warn("warning:"), e.Message)
+	}
+}
+
+func (r *textReporter) Success(msg string) {
+	r.endFields()
+	fmt.Fprintf(r.w, "  %s %s\n\n", r.success(r.check), msg)
+}
+
+func (r *textReporter) Conflicts(cs []generator.Conflict) {
+	if len(cs) == 0 {
+		return
+	}
+	fmt.Fprintf(r.w, "  %s\n", r.warn("Existing files:"))
+	for _, c := range cs {
+		fmt.Fprintf(r.w, "    %s\n", conflictSummary(c))
+	}
+	fmt.Fprintln(r.w)
+}
+
+func (r *textReporter) Upgraded(u generator.FileUpgrade) {
+	// The files are a list like the settings, followed by a blank line
+	r.inFields = true
+	switch u.Action {
+	case generator.UpgradeUnchanged:
+	case generator.UpgradeConflict:
+		fmt.Fprintf(r.w, "  %s %-10s %s (%s)\n", r.fail(r.cross), u.Action, u.Path, upgradeNote(u))
+	case generator.UpgradeKept, generator.UpgradeSkipped:
+		fmt.Fprintf(r.w, "  %s %-10s %s\n", r.warn(r.dot), u.Action, u.Path)
+	default:
+		fmt.Fprintf(r.w, "  %s %-10s %s\n", r.success(r.check), u.Action, u.Path)
+	}
+}
+
+// Drifted prints the diff of d to stdout, so it can be applied as a patch
+func (r *textReporter) Drifted(d generator.FileDrift) {
+	if d.Status == generator.DriftUnchanged {
+		return
+	}
+	fmt.Fprintf(r.out, "diff --git a/%s b/%s\n", d.Path, d.Path)
+	fmt.Fprint(r.out, d.Diff)
+}
+
+// upgradeNote describes the conflicts of u
+func upgradeNote(u generator.FileUpgrade) string {
+	note := fmt.Sprintf("%d conflict(s)", u.Conflicts)
+	if u.Reject != "" {
+		note += ", see " + u.Reject
+	}
+	return note
+}
+
+func (r *textReporter) NextSteps(cmds []string) {
+	fmt.Fprintf(r.w, "  %s\n", r.warn("Next steps:"))
+	for _, c := range cmds {
+		fmt.Fprintf(r.w, "    %s\n", c)
+	}
+	fmt.Fprintln(r.w)
+}
+
+// quietReporter only prints warnings
+type quietReporter struct {
+	w io.Writer
+}
+
+func (quietReporter) Banner()                  {}
+func (quietReporter) Field(name, value string) {}
+func (quietReporter) Success(msg string)       {}
+func (quietReporter) NextSteps(cmds []string)  {}
+
+func (quietReporter) Conflicts(cs []generator.Conflict) {}
+func (quietReporter) Upgraded(u generator.FileUpgrade)  {}
+func (quietReporter) Drifted(d generator.FileDrift)     {}
+
+func (r quietReporter) Report(e generator.Event) {
+	if e.Kind == generator.EventWarning {
+		fmt.Fprintf(r.w, "warning: %s\n", e.Message)
+	}
+}
+
+// jsonReporter writes one JSON object per event and message
+type jsonReporter struct {
+	enc *json.Encoder
+	// warnings are kept for the result of the command
+	warnings []string
+}
+
+// jsonEvent is the line written by jsonReporter
+type jsonEvent struct {
+	Event      string   `json:"event"`
+	Message    string   `json:"message,omitempty"`
+	Path       string   `json:"path,omitempty"`
+	Dir        bool     `json:"dir,omitempty"`
+	Size       int      `json:"size,omitempty"`
+	Hook       string   `json:"hook,omitempty"`
+	DurationMS *float64 `json:"durationMs,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	Value      string   `json:"value,omitempty"`
+	Commands   []string `json:"commands,omitempty"`
+
+	Conflicts []generator.Conflict `json:"conflicts,omitempty"`
+}
+
+func (r *jsonReporter) write(e jsonEvent) {
+	// Nothing sensible is left to do when stdout fails
+	_ = r.enc.Encode(e)
+}
+
+func (r *jsonReporter) Banner() {}
+
+func (r *jsonReporter) Field(name, value string) {
+	r.write(jsonEvent{Event: "field", Name: name, Value: value})
+}
+
+func (r *jsonReporter) Success(msg string) {
+	r.write(jsonEvent{Event: "success", Message: msg})
+}
+
+func (r *jsonReporter) Conflicts(cs []generator.Conflict) {
+	if len(cs) > 0 {
+		r.write(jsonEvent{Event: "conflicts", Conflicts: cs})
+	}
+}
+
+func (r *jsonReporter) Upgraded(u generator.FileUpgrade) {
+	if u.Action == generator.UpgradeUnchanged {
+		return
+	}
+	e := jsonEvent{Event: "file_upgraded", Path: u.Path, Status: string(u.Action)}
+	if u.Action == generator.UpgradeConflict {
+		e.Message = upgradeNote(u)
+	}
+	r.write(e)
+}
+
+func (r *jsonReporter) Drifted(d generator.FileDrift) {
+	if d.Status != generator.DriftUnchanged {
+		r.write(jsonEvent{Event: "file_drifted", Path: d.Path, Status: string(d.Status)})
+	}
+}
+
+func (r *jsonReporter) NextSteps(cmds []string) {
+	r.write(jsonEvent{Event: "next_steps", Commands: cmds})
+}
+
+// writeJSON prints v as an indented JSON document
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (r *jsonReporter) Report(e generator.Event) {
+	out := jsonEvent{
+		Event:   string(e.Kind),
+		Message: e.Message,
+		Path:    e.Path,
+		Dir:     e.Dir,
+		Size:    e.Size,
+		Hook:    e.Hook,
+	}
+	if e.Kind == generator.EventStepFinished {
+		ms := float64(e.Duration) / float64(time.Millisecond)
+		out.DurationMS = &ms
+	}
+	if e.Err != nil {
+		out.Error = e.Err.Error()
+	}
+	if e.Kind == generator.EventWarning {
+		r.warnings = append(r.warnings, e.Message)
+	}
+	r.write(out)
+}
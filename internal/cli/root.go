@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"errors"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -30,6 +32,32 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// ExitError is returned by commands that need a specific exit status
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit status for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *ExitError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 1
+}
+
 func init() {
 	// Disable completion command
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/spec"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of project spec files",
+	Long: `Print the JSON Schema that spec files for goscaffold new --from are
+validated against. Spec files may be written in YAML or JSON.
+
+Example spec:
+  name: billing
+  modulePrefix: go.example.com/team
+  template: api
+  docker: true
+  ci: true
+  lint: true
+  variables:
+    port: 8080
+
+Examples:
+  goscaffold schema > goscaffold.schema.json
+  goscaffold new --from spec.yaml
+  cat spec.json | goscaffold new --from -`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(spec.Schema())
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// specFlags are the flags of goscaffold new that may accompany --from;
+// everything else about the project comes from the spec
+var specFlags = map[string]bool{
+	"from":           true,
+	"dry-run":        true,
+	"contents":       true,
+	"diff":           true,
+	"output-archive": true,
+	"no-hooks":       true,
+	"trust-hooks":    true,
+	"offline":        true,
+	"proxy-dir":      true,
+	"into-existing":  true,
+	"on-conflict":    true,
+	"output":         true,
+	"quiet":          true,
+	"verbose":        true,
+}
+
+// configFromSpec fills config from the spec named by --from. Nothing else
+// is consulted: no prompts, configuration files or environment variables.
+func configFromSpec(cmd *cobra.Command, args []string) (newPlan, error) {
+	var plan newPlan
+
+	var conflicting []string
+	if len(args) > 0 {
+		conflicting = append(conflicting, "a project name argument")
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !specFlags[f.Name] {
+			conflicting = append(conflicting, "--"+f.Name)
+		}
+	})
+	if len(conflicting) > 0 {
+		return plan, fmt.Errorf("--from cannot be combined with %s; set them in the spec instead", strings.Join(conflicting, ", "))
+	}
+
+	var r io.Reader = os.Stdin
+	name := "stdin"
+	if fromSpec != "-" {
+		f, err := os.Open(fromSpec)
+		if err != nil {
+			return plan, err
+		}
+		defer f.Close()
+		r, name = f, fromSpec
+	}
+
+	s, err := spec.Parse(name, r)
+	if err != nil {
+		return plan, err
+	}
+
+	noInteractive = true
+	config = ProjectConfig{
+		Name:             s.Name,
+		ModulePath:       s.ResolvedModulePath(),
+		Template:         s.Template,
+		TemplateDir:      s.TemplateDir,
+		GitHubUser:       s.GitHub,
+		Host:             s.Host,
+		ModulePrefix:     s.ModulePrefix,
+		IncludeMake:      s.Makefile,
+		IncludeDocker:    s.Docker,
+		IncludeCI:        s.CI,
+		IncludeLint:      s.Lint,
+		IncludePreCommit: s.PreCommit,
+		IncludeTests:     s.Tests,
+		InitGit:          s.Git,
+		Tidy:             s.Tidy,
+		License:          s.License,
+		Author:           s.Author,
+		GoVersion:        s.GoVersion,
+		Variables:        s.Variables,
+	}
+
+	if err := checkProjectName(config.Name); err != nil {
+		return plan, err
+	}
+	if err := checkModulePath(s.Vanity); err != nil {
+		return plan, err
+	}
+	if plan.template, err = resolveNewTemplate(); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
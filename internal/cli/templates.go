@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+// previewName is the project name templates are previewed with
+const previewName = "myproject"
+
+var (
+	previewConfig      generator.Config
+	previewVars        []string
+	previewAllDevOps   bool
+	previewAllQuality  bool
+	previewTemplateDir string
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List, describe and preview project templates",
+	Long: `List, describe and preview the templates goscaffold new can use: the
+built-in templates and template directories found in $GOSCAFFOLD_TEMPLATE_PATH
+and the goscaffold/templates folder of your user config directory.
+
+Examples:
+  goscaffold templates list
+  goscaffold templates show api
+  goscaffold templates render api -D --var port=9090`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplatesList,
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:   "show <template>",
+	Short: "Describe a template and the tree it generates",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTemplatesShow,
+}
+
+var templatesRenderCmd = &cobra.Command{
+	Use:   "render <template>",
+	Short: "Print the files a template generates",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTemplatesRender,
+}
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesListCmd, templatesShowCmd, templatesRenderCmd)
+
+	for _, c := range []*cobra.Command{templatesShowCmd, templatesRenderCmd} {
+		c.Flags().StringVar(&previewTemplateDir, "template-dir", "", "Preview the template directory at this path")
+		c.Flags().StringVar(&previewConfig.Name, "name", previewName, "Project name to render with")
+		c.Flags().StringVarP(&previewConfig.ModulePath, "module", "m", "", "Module path to render with (default: the project name)")
+		c.Flags().BoolVar(&previewConfig.IncludeMakefile, "makefile", false, "Include Makefile")
+		c.Flags().BoolVar(&previewConfig.IncludeDocker, "docker", false, "Include Dockerfile and docker-compose")
+		c.Flags().BoolVar(&previewConfig.IncludeCI, "ci", false, "Include GitHub Actions CI workflow")
+		c.Flags().BoolVarP(&previewAllDevOps, "all-devops", "D", false, "Include all DevOps files")
+		c.Flags().BoolVar(&previewConfig.IncludeLint, "lint", false, "Include golangci-lint config")
+		c.Flags().BoolVar(&previewConfig.IncludePreCommit, "precommit", false, "Include pre-commit hooks config")
+		c.Flags().BoolVar(&previewConfig.IncludeTests, "tests", false, "Include test file scaffolding")
+		c.Flags().BoolVarP(&previewAllQuality, "all-quality", "Q", false, "Include all quality tools")
+		c.Flags().StringVar(&previewConfig.GoVersion, "go-version", "", "Go version to render with (default: the version of the go command)")
+		c.Flags().StringArrayVar(&previewVars, "var", nil, "Set a template variable (key=value, repeatable)")
+	}
+}
+
+func runTemplatesList(cmd *cobra.Command, args []string) error {
+	for _, err := range registerSearchPath() {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tDESCRIPTION\tSOURCE")
+	for _, t := range generator.Templates() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name(), t.Version(), t.Description(), generator.TemplateSource(t))
+	}
+	return w.Flush()
+}
+
+func runTemplatesShow(cmd *cobra.Command, args []string) error {
+	tmpl, cfg, err := previewTemplate(args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(strings.TrimSpace(tmpl.Name() + " " + tmpl.Version()))
+	fmt.Printf("  %s\n\n", tmpl.Description())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Source:\t%s\n", generator.TemplateSource(tmpl))
+	fmt.Fprintf(w, "Entrypoint:\t%s\n", tmpl.Entrypoint(cfg))
+	fmt.Fprintf(w, "Run:\t%s\n", tmpl.RunCommand(cfg))
+	fmt.Fprintf(w, "Options:\t%s\n", strings.Join(templateOptions(tmpl, cfg), ", "))
+	if dt, ok := tmpl.(*generator.DirTemplate); ok {
+		var names []string
+		for _, p := range dt.Presets() {
+			names = append(names, p.Name)
+		}
+		if len(names) > 0 {
+			fmt.Fprintf(w, "Presets:\t%s\n", strings.Join(names, ", "))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if vars := generator.TemplateVariables(tmpl); len(vars) > 0 {
+		fmt.Println("\nVariables:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, v := range vars {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", v.Name, variableType(v), variableDetails(v))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if h := generator.TemplateHooks(tmpl); !h.Empty() {
+		fmt.Println("\nHooks:")
+		printHooks(h)
+	}
+
+	fmt.Println()
+	entries, err := renderPreview(cfg)
+	if err != nil {
+		// Describe the template even when required variables are missing
+		fmt.Printf("No preview: %v\n", err)
+		return nil
+	}
+	printTree(os.Stdout, cfg.Name, entries)
+	return nil
+}
+
+func runTemplatesRender(cmd *cobra.Command, args []string) error {
+	_, cfg, err := previewTemplate(args)
+	if err != nil {
+		return err
+	}
+	entries, err := renderPreview(cfg)
+	if err != nil {
+		return err
+	}
+
+	printContents(os.Stdout, entries)
+	return nil
+}
+
+// previewTemplate resolves the template named in args or --template-dir
+// and returns the configuration to preview it with
+func previewTemplate(args []string) (generator.Template, generator.Config, error) {
+	cfg := previewConfig
+	if len(args) > 0 {
+		cfg.Template = args[0]
+	}
+	if cfg.Template == "" && previewTemplateDir == "" {
+		return nil, cfg, fmt.Errorf("name a template or pass --template-dir (see goscaffold templates list)")
+	}
+	cfg.TemplateDir = previewTemplateDir
+
+	tmpl, err := generator.ResolveTemplate(cfg.Template, cfg.TemplateDir)
+	if err != nil {
+		return nil, cfg, err
+	}
+	cfg.Template = tmpl.Name()
+
+	if cfg.ModulePath == "" {
+		cfg.ModulePath = cfg.Name
+	}
+	if cfg.GoVersion == "" {
+		cfg.GoVersion = generator.DetectGoVersion()
+	}
+	if previewAllDevOps {
+		cfg.IncludeMakefile, cfg.IncludeDocker, cfg.IncludeCI = true, true, true
+	}
+	if previewAllQuality {
+		cfg.IncludeLint, cfg.IncludePreCommit, cfg.IncludeTests = true, true, true
+	}
+	if cfg.Variables, err = parseVars(previewVars); err != nil {
+		return nil, cfg, err
+	}
+	return tmpl, cfg, nil
+}
+
+// renderPreview generates the project described by cfg in memory. The
+// generation manifest is left out since it does not come from the template.
+func renderPreview(cfg generator.Config) ([]generator.Entry, error) {
+	gen := generator.New(cfg, generator.WithOutput(generator.NewMemFS()), generator.WithQuiet(), generator.WithToolVersion(versionStr))
+	if err := gen.Generate(); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	var entries []generator.Entry
+	for _, e := range gen.Entries() {
+		if e.Path != generator.ProjectManifestFile {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// templateOptions lists the components that change the output of tmpl
+func templateOptions(tmpl generator.Template, cfg generator.Config) []string {
+	options := []string{"makefile", "docker", "ci", "lint", "precommit"}
+
+	cfg.IncludeTests = true
+	for _, f := range tmpl.Files(cfg) {
+		if strings.HasSuffix(f.Path, "_test.go") {
+			options = append(options, "tests")
+			break
+		}
+	}
+	return append(options, "git")
+}
+
+// variableType describes the type of v, including enum options
+func variableType(v generator.Variable) string {
+	if v.Type == generator.VarEnum {
+		return "enum(" + strings.Join(v.Options, "|") + ")"
+	}
+	return string(v.Type)
+}
+
+// variableDetails summarizes help, default, pattern and condition of v
+func variableDetails(v generator.Variable) string {
+	var parts []string
+	if v.Help != "" {
+		parts = append(parts, v.Help)
+	}
+	if v.Default != "" {
+		parts = append(parts, "default "+v.Default)
+	} else if v.Type != generator.VarList {
+		parts = append(parts, "required")
+	}
+	if v.Pattern != "" {
+		parts = append(parts, "matches "+v.Pattern)
+	}
+	if v.When != "" {
+		parts = append(parts, "when "+v.When)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parseVars turns key=value pairs into a variables map
+func parseVars(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: want key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeDryRun bool
+	upgradeReject bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Apply template updates to a generated project",
+	Long: `Re-render the project in the current directory from the configuration
+recorded in .goscaffold.json with the current templates and merge the result
+into the project.
+
+Files left as generated are replaced. Files edited locally are merged three
+ways: the originally generated content (found in git history by the hash in
+the manifest) is the common base, your version is one side and the new
+template output the other. Overlapping changes are written as git-style
+conflict markers, or with --reject your version is kept and the template
+changes are written to <file>.rej.
+
+Files deleted locally stay deleted.
+
+Examples:
+  goscaffold upgrade --dry-run
+  goscaffold upgrade
+  goscaffold upgrade --reject`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "Show what would change without writing anything")
+	upgradeCmd.Flags().BoolVar(&upgradeReject, "reject", false, "Keep local content on conflicts and write template changes to .rej files")
+	addOutputFlags(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	// Conflicts are reported as an error; the usage text adds nothing
+	cmd.SilenceUsage = true
+
+	// With --output json, stdout carries the result document and the
+	// events go to stderr
+	events := io.Writer(os.Stdout)
+	if outputFormat == outputJSON {
+		events = os.Stderr
+	}
+	r, err := newReporter(events)
+	if err != nil {
+		return err
+	}
+	r.Banner()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	gen := generator.New(generator.Config{},
+		generator.WithOutput(generator.NewOSFS(dir)),
+		generator.WithToolVersion(versionStr),
+		generator.WithQuiet(),
+	)
+	results, err := gen.Upgrade(generator.UpgradeOptions{DryRun: upgradeDryRun, Reject: upgradeReject})
+	if err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+
+	res := upgradeResult{Dir: dir, DryRun: upgradeDryRun, Files: []generator.FileUpgrade{}}
+	for _, u := range results {
+		r.Upgraded(u)
+		switch u.Action {
+		case generator.UpgradeUnchanged:
+			continue
+		case generator.UpgradeConflict:
+			res.Conflicts++
+		case generator.UpgradeKept, generator.UpgradeSkipped:
+		default:
+			res.Changed++
+		}
+		res.Files = append(res.Files, u)
+	}
+
+	switch {
+	case upgradeDryRun:
+		r.Success(fmt.Sprintf("Dry run: %d file(s) would change, %d with conflicts", res.Changed, res.Conflicts))
+	case res.Conflicts > 0:
+	case res.Changed == 0:
+		r.Success("Project is up to date")
+	default:
+		r.Success(fmt.Sprintf("Upgraded %d file(s)", res.Changed))
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, res); err != nil {
+			return err
+		}
+	}
+	if res.Conflicts > 0 && !upgradeDryRun {
+		return fmt.Errorf("%d file(s) have conflicts; resolve them and commit the result", res.Conflicts)
+	}
+	return nil
+}
+
+// upgradeResult is the JSON form of the goscaffold upgrade output
+type upgradeResult struct {
+	Dir    string `json:"dir"`
+	DryRun bool   `json:"dryRun,omitempty"`
+	// Changed counts the files updated, added or merged cleanly
+	Changed   int `json:"changed"`
+	Conflicts int `json:"conflicts"`
+	// Files lists every file that did not stay unchanged
+	Files []generator.FileUpgrade `json:"files"`
+}
@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"runtime"
 
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +16,8 @@ var (
 	dateStr    string
 )
 
+var versionOutput string
+
 // SetVersionInfo sets the version information from main
 func SetVersionInfo(version, commit, date string) {
 	versionStr = version
@@ -23,7 +28,15 @@ func SetVersionInfo(version, commit, date string) {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch versionOutput {
+		case outputJSON:
+			return writeJSON(os.Stdout, newVersionInfo())
+		case outputText:
+		default:
+			return fmt.Errorf("unknown output format %q (want text or json)", versionOutput)
+		}
+
 		cyan := color.New(color.FgCyan).SprintFunc()
 		white := color.New(color.FgWhite).SprintFunc()
 
@@ -32,9 +45,45 @@ var versionCmd = &cobra.Command{
 		fmt.Printf("  %s   %s\n", cyan("commit:"), white(commitStr))
 		fmt.Printf("  %s    %s\n", cyan("built:"), white(dateStr))
 		fmt.Println()
+		return nil
 	},
 }
 
+// versionInfo is the JSON form of the version command output
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	// Templates are the templates built into the binary
+	Templates []templateVersion `json:"templates"`
+}
+
+type templateVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func newVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:   versionStr,
+		Commit:    commitStr,
+		Date:      dateStr,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Templates: []templateVersion{},
+	}
+	for _, t := range generator.Templates() {
+		if generator.TemplateSource(t) == generator.BuiltinSource {
+			info.Templates = append(info.Templates, templateVersion{Name: t.Name(), Version: t.Version()})
+		}
+	}
+	return info
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", outputText, "Output format: text or json")
 }
@@ -0,0 +1,88 @@
+// Package diff implements line-based diffing and three-way merging of
+// text files.
+package diff
+
+import "strings"
+
+// SplitLines splits s into lines, keeping the trailing newline of each line
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// match returns, for every line of a, the index of the line of b it is
+// paired with in a longest common subsequence, or -1. It uses Myers'
+// O(ND) algorithm.
+func match(a, b []string) []int {
+	n, m := len(a), len(b)
+	matches := make([]int, n)
+	for i := range matches {
+		matches[i] = -1
+	}
+	if n == 0 || m == 0 {
+		// Nothing can be paired
+		return matches
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				backtrack(trace, offset, n, m, matches)
+				return matches
+			}
+		}
+	}
+	return matches
+}
+
+// backtrack walks the recorded Myers trace from (n, m) back to the origin
+// and records every diagonal move as a matched pair
+func backtrack(trace [][]int, offset, n, m int, matches []int) {
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches[x] = y
+		}
+		if d > 0 {
+			x, y = prevX, prevY
+		}
+	}
+}
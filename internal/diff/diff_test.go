@@ -0,0 +1,256 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\n\nb\n", []string{"a\n", "\n", "b\n"}},
+	}
+	for _, tt := range tests {
+		if got := SplitLines(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []int
+	}{
+		{"both empty", "", "", []int{}},
+		{"a empty", "", "x\ny\n", []int{}},
+		{"b empty", "x\ny\n", "", []int{-1, -1}},
+		{"identical", "x\ny\nz\n", "x\ny\nz\n", []int{0, 1, 2}},
+		{"disjoint", "x\ny\n", "p\nq\n", []int{-1, -1}},
+		{"insert", "x\nz\n", "x\ny\nz\n", []int{0, 2}},
+		{"delete", "x\ny\nz\n", "x\nz\n", []int{0, -1, 1}},
+		{"replace", "x\ny\nz\n", "x\nq\nz\n", []int{0, -1, 2}},
+		{"single line", "x\n", "x\n", []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := match(SplitLines(tt.a), SplitLines(tt.b))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMatchIsCommonSubsequence checks that match pairs equal lines in
+// increasing order and finds a longest common subsequence
+func TestMatchIsCommonSubsequence(t *testing.T) {
+	a := SplitLines("a\nb\nc\na\nb\nb\na\n")
+	b := SplitLines("c\nb\na\nb\na\nc\n")
+	got := match(a, b)
+
+	last, n := -1, 0
+	for i, j := range got {
+		if j < 0 {
+			continue
+		}
+		if j <= last {
+			t.Fatalf("match = %v: pairs are not increasing", got)
+		}
+		if a[i] != b[j] {
+			t.Fatalf("match = %v: a[%d] = %q paired with b[%d] = %q", got, i, a[i], j, b[j])
+		}
+		last = j
+		n++
+	}
+	// The classic Myers example has an LCS of length 4
+	if n != 4 {
+		t.Errorf("match = %v pairs %d lines, want 4", got, n)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	opts := MergeOptions{OursLabel: "ours", TheirsLabel: "theirs"}
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name: "all empty",
+		},
+		{
+			name:   "empty base and ours",
+			theirs: "a\nb\n",
+			want:   "a\nb\n",
+		},
+		{
+			name: "empty base and theirs",
+			ours: "a\n",
+			want: "a\n",
+		},
+		{
+			name:   "empty base, both sides add the same",
+			ours:   "a\n",
+			theirs: "a\n",
+			want:   "a\n",
+		},
+		{
+			name:      "empty base, both sides add differently",
+			ours:      "a\n",
+			theirs:    "b\n",
+			want:      "<<<<<<< ours\na\n=======\nb\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+		{
+			name: "ours emptied",
+			base: "a\nb\n",
+			ours: "",
+			// theirs unchanged keeps the deletion
+			theirs: "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "identical",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\nd\n",
+			want:   "a\nb\nc\nd\n",
+		},
+		{
+			name:   "separate changes",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "a\nx\nc\n",
+			theirs: "a\nx\nc\n",
+			want:   "a\nx\nc\n",
+		},
+		{
+			name:      "overlapping changes",
+			base:      "a\nb\nc\n",
+			ours:      "a\nx\nc\n",
+			theirs:    "a\ny\nc\n",
+			want:      "a\n<<<<<<< ours\nx\n=======\ny\n>>>>>>> theirs\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:      "overlapping changes without trailing newline",
+			base:      "a\nb",
+			ours:      "a\nx",
+			theirs:    "a\ny",
+			want:      "a\n<<<<<<< ours\nx\n=======\ny\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+		{
+			name:      "two conflicts",
+			base:      "a\nb\nc\nd\ne\n",
+			ours:      "1\nb\nc\nd\n2\n",
+			theirs:    "3\nb\nc\nd\n4\n",
+			want:      "<<<<<<< ours\n1\n=======\n3\n>>>>>>> theirs\nb\nc\nd\n<<<<<<< ours\n2\n=======\n4\n>>>>>>> theirs\n",
+			conflicts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Merge3(tt.base, tt.ours, tt.theirs, opts)
+			if res.Text != tt.want {
+				t.Errorf("Merge3 text = %q, want %q", res.Text, tt.want)
+			}
+			if len(res.Conflicts) != tt.conflicts {
+				t.Errorf("Merge3 conflicts = %d, want %d", len(res.Conflicts), tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMerge3KeepOurs(t *testing.T) {
+	res := Merge3("a\nb\nc\n", "a\nx\nc\n", "a\ny\nc\n", MergeOptions{KeepOurs: true})
+	if want := "a\nx\nc\n"; res.Text != want {
+		t.Errorf("Merge3 text = %q, want %q", res.Text, want)
+	}
+	want := []Conflict{{Line: 2, Base: []string{"b\n"}, Ours: []string{"x\n"}, Theirs: []string{"y\n"}}}
+	if !reflect.DeepEqual(res.Conflicts, want) {
+		t.Errorf("Merge3 conflicts = %+v, want %+v", res.Conflicts, want)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name: "identical",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+		},
+		{
+			name: "from empty",
+			to:   "a\nb\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "to empty",
+			from: "a\n",
+			want: "--- a\n+++ b\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "change in the middle",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:   "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "distant changes make two hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name: "missing newline",
+			from: "a\n",
+			to:   "a\nb",
+			want: "--- a\n+++ b\n@@ -1,1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("a", "b", tt.from, tt.to)
+			if got != tt.want {
+				t.Errorf("Unified =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
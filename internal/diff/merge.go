@@ -0,0 +1,112 @@
+package diff
+
+import "strings"
+
+// Conflict is a region that was changed differently on both sides of a merge
+type Conflict struct {
+	// Line is the 1-based line of the region in the base file
+	Line   int
+	Base   []string
+	Ours   []string
+	Theirs []string
+}
+
+// MergeOptions controls how Merge3 writes conflicting regions
+type MergeOptions struct {
+	// OursLabel and TheirsLabel follow the conflict markers
+	OursLabel   string
+	TheirsLabel string
+	// KeepOurs resolves conflicts in favour of ours instead of writing
+	// conflict markers; the conflicts are still reported
+	KeepOurs bool
+}
+
+// MergeResult is the outcome of Merge3
+type MergeResult struct {
+	// Text is the merged file; conflicting regions carry git-style markers
+	// unless MergeOptions.KeepOurs is set
+	Text      string
+	Conflicts []Conflict
+}
+
+// Merge3 merges the changes made from base to ours and from base to theirs.
+// Regions changed on only one side take that side; regions changed
+// differently on both sides become conflicts.
+func Merge3(base, ours, theirs string, opts MergeOptions) MergeResult {
+	b, o, t := SplitLines(base), SplitLines(ours), SplitLines(theirs)
+	mo, mt := match(b, o), match(b, t)
+
+	var out strings.Builder
+	var conflicts []Conflict
+	i, j, k := 0, 0, 0
+
+	for i < len(b) || j < len(o) || k < len(t) {
+		// Lines unchanged on both sides
+		if i < len(b) && mo[i] == j && mt[i] == k {
+			out.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Find the next base line that both sides kept
+		ni := i
+		for ni < len(b) && (mo[ni] < 0 || mt[ni] < 0) {
+			ni++
+		}
+		nj, nk := len(o), len(t)
+		if ni < len(b) {
+			nj, nk = mo[ni], mt[ni]
+		}
+
+		bc, oc, tc := b[i:ni], o[j:nj], t[k:nk]
+		switch {
+		case equal(oc, bc):
+			writeLines(&out, tc)
+		case equal(tc, bc), equal(oc, tc):
+			writeLines(&out, oc)
+		default:
+			conflicts = append(conflicts, Conflict{Line: i + 1, Base: bc, Ours: oc, Theirs: tc})
+			if opts.KeepOurs {
+				writeLines(&out, oc)
+				break
+			}
+			out.WriteString("<<<<<<< " + opts.OursLabel + "\n")
+			writeTerminated(&out, oc)
+			out.WriteString("=======\n")
+			writeTerminated(&out, tc)
+			out.WriteString(">>>>>>> " + opts.TheirsLabel + "\n")
+		}
+		i, j, k = ni, nj, nk
+	}
+
+	return MergeResult{Text: out.String(), Conflicts: conflicts}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+// writeTerminated writes lines, making sure the last one ends in a newline
+// so conflict markers start on their own line
+func writeTerminated(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+		if !strings.HasSuffix(l, "\n") {
+			out.WriteString("\n")
+		}
+	}
+}
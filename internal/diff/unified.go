@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op is a single line of an edit script
+type op struct {
+	kind opKind
+	line string
+	// a and b are the 0-based positions of the line in from and to
+	a, b int
+}
+
+// editScript turns the matching of a and b into a sequence of operations
+func editScript(a, b []string) []op {
+	matches := match(a, b)
+	var ops []op
+	j := 0
+	for i, m := range matches {
+		if m < 0 {
+			ops = append(ops, op{opDelete, a[i], i, j})
+			continue
+		}
+		for ; j < m; j++ {
+			ops = append(ops, op{opInsert, b[j], i, j})
+		}
+		ops = append(ops, op{opEqual, a[i], i, j})
+		j++
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j], len(a), j})
+	}
+	return ops
+}
+
+// Unified returns the changes from from to to as a unified diff with the
+// given file names in its header, or an empty string if they are equal
+func Unified(fromName, toName, from, to string) string {
+	ops := editScript(SplitLines(from), SplitLines(to))
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == opEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are close enough to share context
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != opEqual {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		lo, hi := max(start-contextLines, 0), min(end+contextLines, len(ops))
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		writeHunk(&out, ops[lo:hi])
+		start = hi
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []op) {
+	aStart, bStart := ops[0].a, ops[0].b
+	aLen, bLen := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			aLen++
+		}
+		if o.kind != opDelete {
+			bLen++
+		}
+	}
+	// Empty ranges are addressed by the line before them
+	if aLen > 0 {
+		aStart++
+	}
+	if bLen > 0 {
+		bStart++
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, o := range ops {
+		out.WriteByte(byte(o.kind))
+		out.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Component is an optional part of a project that can be added later
+type Component struct {
+	Name        string
+	Description string
+
+	enable func(cfg *Config)
+	create func(g *Generator) error
+}
+
+var components = []Component{
+	{"makefile", "Makefile with common targets", func(c *Config) { c.IncludeMakefile = true }, (*Generator).createMakefile},
+	{"docker", "Dockerfile and docker-compose.yml", func(c *Config) { c.IncludeDocker = true }, (*Generator).createDockerFiles},
+	{"ci", "GitHub Actions CI workflow", func(c *Config) { c.IncludeCI = true }, (*Generator).createCIWorkflow},
+	{"lint", "golangci-lint configuration", func(c *Config) { c.IncludeLint = true }, (*Generator).createLintConfig},
+	{"precommit", "pre-commit hooks configuration", func(c *Config) { c.IncludePreCommit = true }, (*Generator).createPreCommitConfig},
+	{"tests", "Test file scaffolding", func(c *Config) { c.IncludeTests = true }, (*Generator).createTestFiles},
+}
+
+// Components returns the components that can be added to an existing project
+func Components() []Component {
+	return append([]Component(nil), components...)
+}
+
+func lookupComponent(name string) (Component, bool) {
+	for _, c := range components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Component{}, false
+}
+
+// Add writes the named components into an existing project. The files are
+// rendered in memory first; unless force is set, Add refuses to run if any
+// of them already exists in the output.
+func (g *Generator) Add(names []string, force bool) error {
+	var selected []Component
+	for _, name := range names {
+		c, ok := lookupComponent(name)
+		if !ok {
+			return fmt.Errorf("unknown component '%s'", name)
+		}
+		c.enable(&g.config)
+		selected = append(selected, c)
+	}
+
+	if err := g.resolveTemplate(); err != nil {
+		return err
+	}
+
+	// Render everything in memory so nothing is written on conflicts
+	out, report := g.out, g.report
+	g.out, g.report = NewMemFS(), nil
+	for _, c := range selected {
+		if err := c.create(g); err != nil {
+			g.out, g.report = out, report
+			return &StepError{Step: "add " + c.Name, Err: err}
+		}
+	}
+	g.out, g.report = out, report
+
+	planned := g.entries
+	g.entries, g.seenDirs, g.seenFiles = nil, nil, nil
+
+	if st, ok := out.(StatFS); ok && !force {
+		var conflicts []string
+		for _, e := range planned {
+			if e.Dir {
+				continue
+			}
+			if _, err := st.Stat(e.Path); err == nil {
+				conflicts = append(conflicts, e.Path)
+			}
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("refusing to overwrite existing files (use --force): %s", strings.Join(conflicts, ", "))
+		}
+	}
+
+	for _, e := range planned {
+		if e.Dir {
+			continue
+		}
+		g.step("Creating " + e.Path + "...")
+		err := g.writeFile(e.Path, string(e.Content))
+		g.endStep(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := g.updateManifest(); err != nil {
+		return &StepError{Step: "update manifest", Err: err}
+	}
+	return nil
+}
+
+// createTestFiles writes the test files of the project template, which
+// must have some
+func (g *Generator) createTestFiles() error {
+	g.step("Creating test files...")
+
+	cfg := g.config
+	cfg.IncludeTests = true
+	files, err := newPathSelector(g.template, newTemplateData(cfg, g.template)).templateFiles(g.template)
+	if err != nil {
+		return err
+	}
+	written := 0
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, "_test.go") {
+			continue
+		}
+		if err := g.writeTemplateFile(f); err != nil {
+			return err
+		}
+		written++
+	}
+	if written == 0 {
+		return fmt.Errorf("tests are not supported for the %s layout, whose template has no test files", g.template.Name())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestAddTests(t *testing.T) {
+	tests := []struct {
+		template string
+		file     string
+	}{
+		{"basic", "main_test.go"},
+		{"api", "internal/handler/handler_test.go"},
+		{"cli", ""},
+		{"grpc", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			out := NewMemFS()
+			g := New(Config{Name: "demo", ModulePath: "example.com/demo", Template: tt.template}, WithOutput(out))
+			err := g.Add([]string{"tests"}, false)
+			if tt.file == "" {
+				if err == nil {
+					t.Fatalf("Add(tests) succeeded, want an error for a template without tests")
+				}
+				if files := out.Files(); len(files) != 0 {
+					t.Errorf("Add(tests) wrote %v", files)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Add(tests): %v", err)
+			}
+			if _, err := out.ReadFile(tt.file); err != nil {
+				t.Errorf("%s not written: %v", tt.file, err)
+			}
+		})
+	}
+}
+
+// unreadableFS is a MemFS whose manifest cannot be read
+type unreadableFS struct {
+	*MemFS
+}
+
+func (u unreadableFS) ReadFile(name string) ([]byte, error) {
+	if name == ProjectManifestFile {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return u.MemFS.ReadFile(name)
+}
+
+func TestAddUpdatesManifest(t *testing.T) {
+	cfg := Config{Name: "demo", ModulePath: "example.com/demo", Template: "basic"}
+
+	// Projects without a manifest get none
+	out := NewMemFS()
+	if err := New(cfg, WithOutput(out)).Add([]string{"makefile"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.ReadFile(ProjectManifestFile); err == nil {
+		t.Error("Add wrote a manifest for a project without one")
+	}
+
+	// Existing manifests record the new files
+	out = generateMem(t, cfg)
+	if err := New(cfg, WithOutput(out)).Add([]string{"makefile"}, false); err != nil {
+		t.Fatal(err)
+	}
+	m, err := out.ReadFile(ProjectManifestFile)
+	if err != nil || !strings.Contains(string(m), `"Makefile"`) {
+		t.Errorf("manifest = %s, %v, want it to list the Makefile", m, err)
+	}
+
+	// A manifest that cannot be read is an error, not a missing one
+	err = New(cfg, WithOutput(unreadableFS{NewMemFS()})).Add([]string{"makefile"}, false)
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Add = %v, want the read error of the manifest", err)
+	}
+}
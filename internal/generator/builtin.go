@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"io/fs"
+	"path"
+)
+
+// builtinVersion is bumped whenever the content of a built-in template changes
+const builtinVersion = "1.0.0"
+
+// builtin is a template shipped inside the goscaffold binary
+type builtin struct {
+	name        string
+	description string
+	// dirs and files may contain template actions, e.g. cmd/{{.Name}}
+	dirs       []string
+	files      []File
+	rules      []Rule
+	requires   []string
+	entrypoint func(cfg Config) string
+}
+
+func (b *builtin) Name() string        { return b.name }
+func (b *builtin) Description() string { return b.description }
+func (b *builtin) Version() string     { return builtinVersion }
+
+func (b *builtin) Directories(cfg Config) []string { return b.dirs }
+func (b *builtin) Files(cfg Config) []File         { return b.files }
+func (b *builtin) Rules() []Rule                   { return b.rules }
+func (b *builtin) Requires() []string              { return b.requires }
+
+func (b *builtin) Entrypoint(cfg Config) string {
+	return b.entrypoint(cfg)
+}
+
+func (b *builtin) RunCommand(cfg Config) string {
+	return "go run " + b.Entrypoint(cfg)
+}
+
+func (b *builtin) Readme(cfg Config) string {
+	content, err := fs.ReadFile(templateFS, path.Join("templates", b.name, "readme.md.tmpl"))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// embedded returns a File rendered from the embedded template name
+func embedded(dest, name string) File {
+	return File{Path: dest, FS: templateFS, Source: path.Join("templates", name)}
+}
+
+// testRules limits test files to projects with test scaffolding
+var testRules = []Rule{{Path: "**/*_test.go", When: ".IncludeTests"}}
+
+// cmdEntrypoint is the entrypoint of templates with a cmd/<name> layout
+func cmdEntrypoint(cfg Config) string {
+	return "./cmd/" + cfg.Name
+}
+
+func init() {
+	for _, t := range []*builtin{
+		basicTemplate,
+		cliTemplate,
+		apiTemplate,
+		grpcTemplate,
+		libraryTemplate,
+	} {
+		if err := Register(t); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ============================================================================
+// Basic Template
+// ============================================================================
+
+var basicTemplate = &builtin{
+	name:        "basic",
+	description: "Minimal Go project",
+	files: []File{
+		embedded("main.go", "basic/main.go.tmpl"),
+		embedded("main_test.go", "basic/main_test.go.tmpl"),
+	},
+	rules:      testRules,
+	entrypoint: func(cfg Config) string { return "." },
+}
+
+// ============================================================================
+// CLI Template (Cobra)
+// ============================================================================
+
+var cliTemplate = &builtin{
+	name:        "cli",
+	description: "CLI application with Cobra",
+	dirs: []string{
+		"cmd/{{.Name}}",
+		"internal",
+	},
+	files: []File{
+		embedded("cmd/{{.Name}}/main.go", "cli/main.go.tmpl"),
+		embedded("internal/cmd/root.go", "cli/root.go.tmpl"),
+		embedded("internal/cmd/version.go", "cli/version.go.tmpl"),
+	},
+	requires:   []string{"github.com/spf13/cobra"},
+	entrypoint: cmdEntrypoint,
+}
+
+// ============================================================================
+// API Template (Chi Router)
+// ============================================================================
+
+var apiTemplate = &builtin{
+	name:        "api",
+	description: "REST API with Chi router",
+	dirs: []string{
+		"cmd/{{.Name}}",
+		"internal/handler",
+		"internal/middleware",
+		"internal/router",
+		"pkg",
+	},
+	files: []File{
+		embedded("cmd/{{.Name}}/main.go", "api/main.go.tmpl"),
+		embedded("internal/router/router.go", "api/router.go.tmpl"),
+		embedded("internal/handler/handler.go", "api/handler.go.tmpl"),
+		embedded("internal/middleware/middleware.go", "api/middleware.go.tmpl"),
+		embedded("internal/handler/handler_test.go", "api/handler_test.go.tmpl"),
+	},
+	rules:      testRules,
+	requires:   []string{"github.com/go-chi/chi/v5"},
+	entrypoint: cmdEntrypoint,
+}
+
+// ============================================================================
+// gRPC Template
+// ============================================================================
+
+var grpcTemplate = &builtin{
+	name:        "grpc",
+	description: "gRPC service with proto files",
+	dirs: []string{
+		"cmd/{{.Name}}",
+		"internal/server",
+		"proto",
+		"pkg",
+	},
+	files: []File{
+		embedded("cmd/{{.Name}}/main.go", "grpc/main.go.tmpl"),
+		embedded("internal/server/server.go", "grpc/server.go.tmpl"),
+		embedded("proto/{{.Name}}.proto", "grpc/service.proto.tmpl"),
+	},
+	requires:   []string{"google.golang.org/grpc"},
+	entrypoint: cmdEntrypoint,
+}
+
+// ============================================================================
+// Library Template
+// ============================================================================
+
+var libraryTemplate = &builtin{
+	name:        "library",
+	description: "Reusable Go library",
+	dirs: []string{
+		"pkg/{{.Name}}",
+		"examples",
+	},
+	files: []File{
+		embedded("pkg/{{.Name}}/{{.Name}}.go", "library/library.go.tmpl"),
+		embedded("examples/basic/main.go", "library/example.go.tmpl"),
+		embedded("pkg/{{.Name}}/{{.Name}}_test.go", "library/library_test.go.tmpl"),
+	},
+	rules:      testRules,
+	entrypoint: func(cfg Config) string { return "./examples/basic" },
+}
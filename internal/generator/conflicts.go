@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ConflictPolicy decides what happens to a file that already exists when
+// generating into an existing directory
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing file
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing file
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictBackup copies the existing file to <file>.orig and writes
+	// the new one
+	ConflictBackup ConflictPolicy = "backup"
+	// ConflictPrompt asks ConflictOptions.Prompt
+	ConflictPrompt ConflictPolicy = "prompt"
+	// ConflictMerge appends the lines missing from the existing file; it
+	// only applies to ignore files such as .gitignore
+	ConflictMerge ConflictPolicy = "merge"
+)
+
+// backupSuffix is appended to the path of a file to keep its original
+const backupSuffix = ".orig"
+
+// ParseConflictPolicy validates the name of a conflict policy
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case ConflictSkip, ConflictOverwrite, ConflictBackup, ConflictPrompt, ConflictMerge:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %q (want skip, overwrite, backup, prompt or merge)", s)
+}
+
+// CanMerge reports whether ConflictMerge applies to the slash-separated
+// name: files listing one pattern per line, like .gitignore
+func CanMerge(name string) bool {
+	return strings.HasSuffix(path.Base(name), "ignore")
+}
+
+// ConflictRule applies Policy to the files matching Pattern, a glob in
+// which ** matches any number of directories
+type ConflictRule struct {
+	Pattern string
+	Policy  ConflictPolicy
+}
+
+// ConflictResolver asks what to do with the existing file name. It must
+// not return ConflictPrompt.
+type ConflictResolver func(name string, existing, generated []byte) (ConflictPolicy, error)
+
+// ConflictOptions control how existing files are treated
+type ConflictOptions struct {
+	// Default applies to files no rule matches
+	Default ConflictPolicy
+	// Rules are tried in order; the first match wins
+	Rules []ConflictRule
+	// Prompt decides for ConflictPrompt
+	Prompt ConflictResolver
+}
+
+// Check validates the policies and patterns of o
+func (o ConflictOptions) Check() error {
+	if o.Default == ConflictMerge {
+		return fmt.Errorf("merge only applies to ignore files and cannot be the default conflict policy")
+	}
+	policies := []ConflictPolicy{o.Default}
+	for _, r := range o.Rules {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid conflict pattern %q: %w", r.Pattern, err)
+		}
+		if r.Policy == ConflictMerge && !CanMerge(r.Pattern) {
+			return fmt.Errorf("merge only applies to ignore files such as .gitignore, not %s", r.Pattern)
+		}
+		policies = append(policies, r.Policy)
+	}
+	for _, p := range policies {
+		if _, err := ParseConflictPolicy(string(p)); err != nil {
+			return err
+		}
+		if p == ConflictPrompt && o.Prompt == nil {
+			return fmt.Errorf("the prompt conflict policy needs an interactive terminal")
+		}
+	}
+	return nil
+}
+
+// policy returns the policy for the slash-separated name
+func (o ConflictOptions) policy(name string) ConflictPolicy {
+	for _, r := range o.Rules {
+		if matchGlob(r.Pattern, name) {
+			return r.Policy
+		}
+	}
+	return o.Default
+}
+
+// Conflict records how an existing file was treated
+type Conflict struct {
+	Path string `json:"path"`
+	// Policy is the policy applied, never ConflictPrompt
+	Policy ConflictPolicy `json:"policy"`
+	// Backup is the path the original was copied to with ConflictBackup
+	Backup string `json:"backup,omitempty"`
+}
+
+// WithConflicts makes Generate write into the project directory if it
+// already exists, treating existing files as opts says, instead of
+// refusing to run
+func WithConflicts(opts ConflictOptions) Option {
+	return func(g *Generator) {
+		g.conflicts = &opts
+	}
+}
+
+// Conflicts returns the existing files Generate came across, in order
+func (g *Generator) Conflicts() []Conflict {
+	return append([]Conflict(nil), g.conflictLog...)
+}
+
+// resolveConflict returns the content to write to name, which may be
+// merged with the existing file, or false if the existing file is kept
+func (g *Generator) resolveConflict(name string, content []byte) ([]byte, bool, error) {
+	r, ok := g.out.(ReadFileFS)
+	if g.conflicts == nil || !ok {
+		return content, true, nil
+	}
+	existing, err := r.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return content, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if bytes.Equal(existing, content) {
+		// Nothing to decide
+		return content, true, nil
+	}
+
+	policy := g.conflicts.policy(name)
+	if policy == ConflictPrompt {
+		if policy, err = g.conflicts.Prompt(name, existing, content); err != nil {
+			return nil, false, err
+		}
+	}
+
+	c := Conflict{Path: name, Policy: policy}
+	switch policy {
+	case ConflictSkip:
+	case ConflictOverwrite:
+	case ConflictBackup:
+		if c.Backup, err = g.backup(r, name, existing); err != nil {
+			return nil, false, err
+		}
+	case ConflictMerge:
+		if !CanMerge(name) {
+			return nil, false, fmt.Errorf("cannot merge %s: merge only applies to ignore files", name)
+		}
+		content = mergeLines(existing, content)
+	default:
+		return nil, false, fmt.Errorf("invalid conflict policy %q for %s", policy, name)
+	}
+	g.conflictLog = append(g.conflictLog, c)
+	return content, policy != ConflictSkip, nil
+}
+
+// backup writes existing to the first free name of <name>.orig,
+// <name>.orig.1, ...
+func (g *Generator) backup(r ReadFileFS, name string, existing []byte) (string, error) {
+	backup := name + backupSuffix
+	for i := 1; ; i++ {
+		if _, err := r.ReadFile(backup); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		backup = fmt.Sprintf("%s%s.%d", name, backupSuffix, i)
+	}
+	if err := g.out.WriteFile(backup, existing, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", name, err)
+	}
+	g.emit(Event{Kind: EventFileWritten, Path: backup, Size: len(existing)})
+	return backup, nil
+}
+
+// mergeLines appends the lines of generated that existing lacks
+func mergeLines(existing, generated []byte) []byte {
+	have := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		have[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, line := range strings.Split(string(generated), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || have[trimmed] {
+			continue
+		}
+		have[trimmed] = true
+		missing = append(missing, line)
+	}
+	if len(missing) == 0 {
+		return existing
+	}
+
+	merged := append([]byte(nil), existing...)
+	if len(merged) > 0 && !bytes.HasSuffix(merged, []byte("\n")) {
+		merged = append(merged, '\n')
+	}
+	if len(merged) > 0 {
+		merged = append(merged, '\n')
+	}
+	merged = append(merged, "# Added by goscaffold\n"+strings.Join(missing, "\n")+"\n"...)
+	return merged
+}
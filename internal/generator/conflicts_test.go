@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveConflict(t *testing.T) {
+	const existing, generated = "old\n", "new\n"
+
+	tests := []struct {
+		name     string
+		file     string
+		policy   ConflictPolicy
+		prompted ConflictPolicy
+		want     string
+		write    bool
+		backup   string
+		wantErr  bool
+	}{
+		{name: "skip", file: "main.go", policy: ConflictSkip, want: generated},
+		{name: "overwrite", file: "main.go", policy: ConflictOverwrite, want: generated, write: true},
+		{name: "backup", file: "main.go", policy: ConflictBackup, want: generated, write: true, backup: "main.go.orig"},
+		{name: "prompt", file: "main.go", policy: ConflictPrompt, prompted: ConflictOverwrite, want: generated, write: true},
+		{name: "merge", file: ".gitignore", policy: ConflictMerge, want: "old\n\n# Added by goscaffold\nnew\n", write: true},
+		{name: "merge non-ignore file", file: "main.go", policy: ConflictMerge, wantErr: true},
+		{name: "prompt answering prompt", file: "main.go", policy: ConflictPrompt, prompted: ConflictPrompt, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := NewMemFS()
+			out.WriteFile(tt.file, []byte(existing), 0644)
+			opts := ConflictOptions{
+				Default: tt.policy,
+				Prompt: func(name string, old, new []byte) (ConflictPolicy, error) {
+					if string(old) != existing || string(new) != generated {
+						t.Errorf("Prompt(%s) got %q, %q", name, old, new)
+					}
+					return tt.prompted, nil
+				},
+			}
+			g := New(Config{Name: "demo"}, WithOutput(out), WithConflicts(opts))
+
+			got, write, err := g.resolveConflict(tt.file, []byte(generated))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if write != tt.write {
+				t.Errorf("write = %v, want %v", write, tt.write)
+			}
+			if write && string(got) != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+
+			want := Conflict{Path: tt.file, Policy: tt.policy, Backup: tt.backup}
+			if tt.prompted != "" {
+				want.Policy = tt.prompted
+			}
+			if log := g.Conflicts(); !reflect.DeepEqual(log, []Conflict{want}) {
+				t.Errorf("Conflicts() = %+v, want %+v", log, want)
+			}
+			if tt.backup != "" {
+				if b, err := out.ReadFile(tt.backup); err != nil || string(b) != existing {
+					t.Errorf("backup %s = %q, %v, want %q", tt.backup, b, err, existing)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveConflictNoConflict(t *testing.T) {
+	out := NewMemFS()
+	out.WriteFile("same.txt", []byte("same\n"), 0644)
+	g := New(Config{Name: "demo"}, WithOutput(out), WithConflicts(ConflictOptions{Default: ConflictSkip}))
+
+	// Missing and identical files are written without consulting the policy
+	for _, name := range []string{"new.txt", "same.txt"} {
+		content, write, err := g.resolveConflict(name, []byte("same\n"))
+		if err != nil || !write || string(content) != "same\n" {
+			t.Errorf("resolveConflict(%s) = %q, %v, %v, want a write", name, content, write, err)
+		}
+	}
+	if log := g.Conflicts(); len(log) != 0 {
+		t.Errorf("Conflicts() = %+v, want none", log)
+	}
+}
+
+func TestResolveConflictBackupName(t *testing.T) {
+	out := NewMemFS()
+	for _, name := range []string{"a.txt", "a.txt.orig", "a.txt.orig.1"} {
+		out.WriteFile(name, []byte(name), 0644)
+	}
+	g := New(Config{Name: "demo"}, WithOutput(out), WithConflicts(ConflictOptions{Default: ConflictBackup}))
+
+	if _, _, err := g.resolveConflict("a.txt", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Conflicts()[0].Backup; got != "a.txt.orig.2" {
+		t.Errorf("backup = %s, want a.txt.orig.2", got)
+	}
+	if b, _ := out.ReadFile("a.txt.orig.2"); string(b) != "a.txt" {
+		t.Errorf("a.txt.orig.2 = %q, want the original", b)
+	}
+}
+
+func TestConflictRules(t *testing.T) {
+	opts := ConflictOptions{
+		Default: ConflictSkip,
+		Rules: []ConflictRule{
+			{Pattern: "**/.*ignore", Policy: ConflictMerge},
+			{Pattern: "**/*.go", Policy: ConflictBackup},
+			{Pattern: "cmd/**", Policy: ConflictOverwrite},
+		},
+	}
+	if err := opts.Check(); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]ConflictPolicy{
+		".gitignore":         ConflictMerge,
+		"web/.dockerignore":  ConflictMerge,
+		"cmd/demo/main.go":   ConflictBackup,
+		"cmd/demo/README.md": ConflictOverwrite,
+		"README.md":          ConflictSkip,
+	}
+	for name, want := range tests {
+		if got := opts.policy(name); got != want {
+			t.Errorf("policy(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestConflictOptionsCheck(t *testing.T) {
+	tests := []struct {
+		opts    ConflictOptions
+		wantErr bool
+	}{
+		{opts: ConflictOptions{Default: ConflictOverwrite}},
+		{opts: ConflictOptions{Default: ConflictMerge}, wantErr: true},
+		{opts: ConflictOptions{Default: ConflictPrompt}, wantErr: true},
+		{opts: ConflictOptions{Default: "ask"}, wantErr: true},
+		{opts: ConflictOptions{Default: ConflictSkip, Rules: []ConflictRule{{"*.go", ConflictMerge}}}, wantErr: true},
+		{opts: ConflictOptions{Default: ConflictSkip, Rules: []ConflictRule{{"[", ConflictSkip}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Check(); (err != nil) != tt.wantErr {
+			t.Errorf("Check(%+v) = %v, wantErr %v", tt.opts, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		existing, generated, want string
+	}{
+		{"bin/\n", "bin/\n", "bin/\n"},
+		{"bin/", "# Binaries\nbin/\n*.exe\n", "bin/\n\n# Added by goscaffold\n*.exe\n"},
+		{"", "bin/\n", "# Added by goscaffold\nbin/\n"},
+		{"  bin/\n", "bin/\nvendor/\nvendor/\n", "  bin/\n\n# Added by goscaffold\nvendor/\n"},
+	}
+	for _, tt := range tests {
+		got := string(mergeLines([]byte(tt.existing), []byte(tt.generated)))
+		if got != tt.want {
+			t.Errorf("mergeLines(%q, %q) = %q, want %q", tt.existing, tt.generated, got, tt.want)
+		}
+	}
+}
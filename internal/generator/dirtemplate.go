@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+	"github.com/azrakarakaya1/goscaffold/internal/userconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the manifest at the root of a template directory
+const ManifestFile = "template.yaml"
+
+// filesDir holds the files and directory layout of a template directory
+const filesDir = "files"
+
+// templatePathEnv lists extra template search directories
+const templatePathEnv = "GOSCAFFOLD_TEMPLATE_PATH"
+
+// Manifest describes a user-supplied template directory
+type Manifest struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+	Entrypoint  string `yaml:"entrypoint"`
+	Run         string `yaml:"run"`
+	Usage       string `yaml:"usage"`
+	// Variables are extra inputs, available to templates as .Variables
+	Variables []Variable `yaml:"variables"`
+	// Rules make files and directories conditional, e.g.
+	// "Dockerfile when .IncludeDocker"
+	Rules []Rule `yaml:"rules"`
+	// GoVersion is the oldest Go version the template code supports
+	GoVersion string `yaml:"goVersion"`
+	// Requires lists the modules the template code imports, as a module
+	// path pinned by goscaffold or as path@version
+	Requires []string `yaml:"requires"`
+	// Hooks are commands run before and after generation; users are asked
+	// to approve them
+	Hooks hooks.Hooks `yaml:"hooks"`
+	// Presets bundle this template with options; see goscaffold presets
+	Presets map[string]userconfig.Preset `yaml:"presets"`
+}
+
+// DirTemplate is a template loaded from a directory on disk
+type DirTemplate struct {
+	Manifest Manifest
+	Dir      string
+
+	dirs  []string
+	files []File
+}
+
+// LoadDirTemplate reads the template stored in dir
+func LoadDirTemplate(dir string) (*DirTemplate, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s is not a template directory: missing %s", dir, ManifestFile)
+		}
+		return nil, err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	if m.Name == "" {
+		m.Name = filepath.Base(dir)
+	}
+	if m.Entrypoint == "" {
+		m.Entrypoint = "."
+	} else if m.Entrypoint != "." && !strings.HasPrefix(m.Entrypoint, "./") {
+		m.Entrypoint = "./" + path.Clean(filepath.ToSlash(m.Entrypoint))
+	}
+	if m.Run == "" {
+		m.Run = "go run " + m.Entrypoint
+	}
+
+	seen := make(map[string]bool)
+	for i := range m.Variables {
+		v := &m.Variables[i]
+		if err := v.check(); err != nil {
+			return nil, fmt.Errorf("template %s: %w", m.Name, err)
+		}
+		if seen[v.Name] {
+			return nil, fmt.Errorf("template %s: variable %s declared twice", m.Name, v.Name)
+		}
+		seen[v.Name] = true
+	}
+
+	for _, r := range m.Rules {
+		if err := r.check(); err != nil {
+			return nil, fmt.Errorf("template %s: %w", m.Name, err)
+		}
+	}
+
+	if m.GoVersion != "" {
+		if _, err := ParseGoVersion(m.GoVersion); err != nil {
+			return nil, fmt.Errorf("template %s: %w", m.Name, err)
+		}
+	}
+	for _, r := range m.Requires {
+		if _, err := parseRequirement(r); err != nil {
+			return nil, fmt.Errorf("template %s: %w", m.Name, err)
+		}
+	}
+	if err := m.Hooks.Check(); err != nil {
+		return nil, fmt.Errorf("template %s: %w", m.Name, err)
+	}
+	for name := range m.Presets {
+		if err := userconfig.CheckPresetName(name); err != nil {
+			return nil, fmt.Errorf("template %s: %w", m.Name, err)
+		}
+	}
+
+	t := &DirTemplate{Manifest: m, Dir: dir}
+	if err := t.scan(); err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", m.Name, err)
+	}
+	return t, nil
+}
+
+// scan records the directory layout and files of the template
+func (t *DirTemplate) scan() error {
+	root := filepath.Join(t.Dir, filesDir)
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return fmt.Errorf("missing %s directory", filesDir)
+	}
+
+	fsys := os.DirFS(root)
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == "." {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			t.dirs = append(t.dirs, p)
+			return nil
+		}
+
+		// Files ending in .tmpl are rendered, everything else is copied as-is
+		file := File{Path: p, FS: fsys, Source: p, Raw: true}
+		if strings.HasSuffix(p, ".tmpl") {
+			file.Path = strings.TrimSuffix(p, ".tmpl")
+			file.Raw = false
+		}
+		t.files = append(t.files, file)
+		return nil
+	})
+}
+
+func (t *DirTemplate) Name() string                    { return t.Manifest.Name }
+func (t *DirTemplate) Description() string             { return t.Manifest.Description }
+func (t *DirTemplate) Version() string                 { return t.Manifest.Version }
+func (t *DirTemplate) Directories(cfg Config) []string { return t.dirs }
+func (t *DirTemplate) Files(cfg Config) []File         { return t.files }
+func (t *DirTemplate) Entrypoint(cfg Config) string    { return t.Manifest.Entrypoint }
+func (t *DirTemplate) RunCommand(cfg Config) string    { return t.Manifest.Run }
+
+// Variables returns the variables declared in the template manifest
+func (t *DirTemplate) Variables() []Variable { return t.Manifest.Variables }
+
+// Rules returns the rules declared in the template manifest
+func (t *DirTemplate) Rules() []Rule { return t.Manifest.Rules }
+
+// MinGoVersion returns the Go version declared in the template manifest
+func (t *DirTemplate) MinGoVersion() string { return strings.TrimPrefix(t.Manifest.GoVersion, "go") }
+
+// Requires returns the requirements declared in the template manifest
+func (t *DirTemplate) Requires() []string { return t.Manifest.Requires }
+
+// Hooks returns the hooks declared in the template manifest
+func (t *DirTemplate) Hooks() hooks.Hooks { return t.Manifest.Hooks }
+
+// Presets returns the presets declared in the template manifest. Presets
+// that name no template use this one.
+func (t *DirTemplate) Presets() []userconfig.Preset {
+	dir := t.Dir
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	var presets []userconfig.Preset
+	for name, p := range t.Manifest.Presets {
+		p.Name, p.Source = name, dir
+		if p.Template == "" && p.TemplateDir == "" {
+			p.TemplateDir = dir
+		}
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets
+}
+
+func (t *DirTemplate) Readme(cfg Config) string {
+	if t.Manifest.Usage == "" {
+		return ""
+	}
+	return `{{define "usage"}}` + strings.TrimRight(t.Manifest.Usage, "\n") + `{{end}}`
+}
+
+// TemplateSearchPath returns the directories searched for named templates
+func TemplateSearchPath() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(templatePathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if cfgDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(cfgDir, "goscaffold", "templates"))
+	}
+	return dirs
+}
+
+// FindDirTemplate looks up the template called name in the search path
+func FindDirTemplate(name string, searchPath []string) (*DirTemplate, error) {
+	for _, dir := range searchPath {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(filepath.Join(candidate, ManifestFile)); err != nil {
+			continue
+		}
+		return LoadDirTemplate(candidate)
+	}
+	return nil, fmt.Errorf("unknown template '%s' (searched: %s)", name, strings.Join(searchPath, ", "))
+}
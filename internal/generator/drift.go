@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/azrakarakaya1/goscaffold/internal/diff"
+)
+
+// DriftStatus describes how a project file compares to the template output
+type DriftStatus string
+
+const (
+	// DriftUnchanged means the file matches the template output
+	DriftUnchanged DriftStatus = "unchanged"
+	// DriftModified means the file differs from the template output
+	DriftModified DriftStatus = "modified"
+	// DriftMissing means the template produces a file the project lacks
+	DriftMissing DriftStatus = "missing"
+)
+
+// FileDrift compares a single project file with the template output
+type FileDrift struct {
+	Path   string      `json:"path"`
+	Status DriftStatus `json:"status"`
+	// Diff is a unified diff from the project file to the template output
+	Diff string `json:"-"`
+}
+
+// Drift re-renders the project with the current templates and compares
+// every file they produce with the project in the output. Files the
+// templates do not produce are ignored, and so are the changes go mod tidy
+// makes to go.mod.
+func (g *Generator) Drift() ([]FileDrift, error) {
+	r, ok := g.out.(ReadFileFS)
+	if !ok {
+		return nil, fmt.Errorf("drift needs an output that can read existing files")
+	}
+
+	if err := g.resolveTemplate(); err != nil {
+		return nil, err
+	}
+	rendered, err := g.renderMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []FileDrift
+	for _, e := range rendered {
+		if e.Dir || e.Path == ProjectManifestFile {
+			continue
+		}
+
+		res := FileDrift{Path: e.Path, Status: DriftUnchanged}
+		current, err := r.ReadFile(e.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			res.Status = DriftMissing
+			res.Diff = diff.Unified("/dev/null", "b/"+e.Path, "", string(e.Content))
+		case err != nil:
+			return nil, err
+		case e.Path == "go.mod" && tidyEqual(current, e.Content):
+			// go mod tidy was run, as generated projects are told to
+		case string(current) != string(e.Content):
+			res.Status = DriftModified
+			res.Diff = diff.Unified("a/"+e.Path, "b/"+e.Path, string(current), string(e.Content))
+		}
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, nil
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTemplateDir creates a template directory holding files, given by
+// slash-separated path relative to the template root
+func writeTemplateDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// generateMem generates the project of cfg in memory
+func generateMem(t *testing.T, cfg Config) *MemFS {
+	t.Helper()
+	out := NewMemFS()
+	if err := New(cfg, WithOutput(out)).Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	return out
+}
+
+func TestDrift(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		"template.yaml":       "name: drift\n",
+		"files/main.go":       "package main\n\nfunc main() {}\n",
+		"files/data/.gitkeep": "",
+	})
+	cfg := Config{Name: "demo", ModulePath: "example.com/demo", TemplateDir: dir}
+
+	out := generateMem(t, cfg)
+	delete(out.files, "data/.gitkeep")
+	out.files["main.go"] = []byte("package main\n\nfunc main() { println() }\n")
+
+	drift, err := New(cfg, WithOutput(out)).Drift()
+	if err != nil {
+		t.Fatalf("Drift: %v", err)
+	}
+
+	status := make(map[string]FileDrift)
+	for _, d := range drift {
+		status[d.Path] = d
+	}
+	if d := status["data/.gitkeep"]; d.Status != DriftMissing {
+		t.Errorf("data/.gitkeep status = %q, want %q", d.Status, DriftMissing)
+	}
+	if d := status["main.go"]; d.Status != DriftModified || d.Diff == "" {
+		t.Errorf("main.go status = %q with diff %q, want %q with a diff", d.Status, d.Diff, DriftModified)
+	}
+	if d := status["go.mod"]; d.Status != DriftUnchanged || d.Diff != "" {
+		t.Errorf("go.mod status = %q with diff %q, want %q", d.Status, d.Diff, DriftUnchanged)
+	}
+	if _, ok := status[ProjectManifestFile]; ok {
+		t.Errorf("Drift reports the project manifest")
+	}
+}
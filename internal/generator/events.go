@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"time"
+)
+
+// EventKind identifies what an Event reports
+type EventKind string
+
+const (
+	// EventStepStarted begins a step; Message describes it, e.g.
+	// "Creating go.mod..."
+	EventStepStarted EventKind = "step_started"
+	// EventStepFinished ends the last step started, with its Duration and
+	// Err
+	EventStepFinished EventKind = "step_finished"
+	// EventFileWritten is sent for every directory and file written
+	EventFileWritten EventKind = "file_written"
+	// EventHookOutput carries a line of output of Hook
+	EventHookOutput EventKind = "hook_output"
+	// EventWarning reports a problem that did not stop the generator
+	EventWarning EventKind = "warning"
+)
+
+// Event reports the progress of a generator
+type Event struct {
+	Kind EventKind
+	// Message is the description of a step, the line of hook output or
+	// the warning
+	Message string
+	// Path is the slash-separated path of the entry written
+	Path string
+	Dir  bool
+	Size int
+	// Hook names the hook that wrote the output
+	Hook     string
+	Duration time.Duration
+	Err      error
+}
+
+// Reporter receives the events of a generator. Events are reported one at
+// a time, from the goroutine running the generator.
+type Reporter interface {
+	Report(e Event)
+}
+
+// ReporterFunc adapts a function to a Reporter
+type ReporterFunc func(e Event)
+
+// Report calls f(e)
+func (f ReporterFunc) Report(e Event) {
+	f(e)
+}
+
+// WithReporter sends the events of the generator to r. Without it, the
+// generator reports nothing.
+func WithReporter(r Reporter) Option {
+	return func(g *Generator) {
+		g.report = r
+	}
+}
+
+// emit sends e to the reporter, if any
+func (g *Generator) emit(e Event) {
+	if g.report != nil {
+		g.report.Report(e)
+	}
+}
+
+// step starts a step described by msg
+func (g *Generator) step(msg string) {
+	if g.report == nil {
+		return
+	}
+	g.stepStart = time.Now()
+	g.emit(Event{Kind: EventStepStarted, Message: msg})
+}
+
+// endStep finishes the step started last, if it is still open
+func (g *Generator) endStep(err error) {
+	if g.stepStart.IsZero() {
+		return
+	}
+	g.emit(Event{Kind: EventStepFinished, Duration: time.Since(g.stepStart), Err: err})
+	g.stepStart = time.Time{}
+}
+
+// warn reports a problem that does not stop the generator
+func (g *Generator) warn(msg string) {
+	g.emit(Event{Kind: EventWarning, Message: msg})
+}
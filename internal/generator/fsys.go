@@ -0,0 +1,344 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WriteFS is the filesystem a project is generated into. Paths are
+// slash-separated and relative to the root of the filesystem.
+type WriteFS interface {
+	MkdirAll(name string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// StatFS is implemented by outputs that can report on existing entries
+type StatFS interface {
+	WriteFS
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// ReadFileFS is implemented by outputs that can read back existing files
+type ReadFileFS interface {
+	WriteFS
+	ReadFile(name string) ([]byte, error)
+}
+
+// ============================================================================
+// OS
+// ============================================================================
+
+// OSFS writes to the operating system below Root
+type OSFS struct {
+	Root string
+}
+
+// NewOSFS returns a WriteFS rooted at dir
+func NewOSFS(dir string) *OSFS {
+	return &OSFS{Root: dir}
+}
+
+func (o *OSFS) path(name string) string {
+	return filepath.Join(o.Root, filepath.FromSlash(name))
+}
+
+// MkdirAll creates the directory name and any missing parents
+func (o *OSFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(o.path(name), perm)
+}
+
+// WriteFile writes data to the file name
+func (o *OSFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(o.path(name), data, perm)
+}
+
+// ReadFile returns the content of the file name
+func (o *OSFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(o.path(name))
+}
+
+// Stat returns information about the entry name
+func (o *OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(o.path(name))
+}
+
+// ============================================================================
+// Sub-directory
+// ============================================================================
+
+type subFS struct {
+	fsys WriteFS
+	dir  string
+}
+
+// SubFS returns a WriteFS that writes below dir inside fsys. Names that
+// would leave dir, like ../x or /x, are rejected.
+func SubFS(fsys WriteFS, dir string) WriteFS {
+	return &subFS{fsys: fsys, dir: dir}
+}
+
+// path returns the path of name inside fsys
+func (s *subFS) path(op, name string) (string, error) {
+	clean := path.Clean(name)
+	if !fs.ValidPath(clean) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return path.Join(s.dir, clean), nil
+}
+
+func (s *subFS) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := s.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return s.fsys.MkdirAll(p, perm)
+}
+
+func (s *subFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := s.path("write", name)
+	if err != nil {
+		return err
+	}
+	return s.fsys.WriteFile(p, data, perm)
+}
+
+// ============================================================================
+// In-memory
+// ============================================================================
+
+// MemFS keeps generated files in memory
+type MemFS struct {
+	mu    sync.Mutex
+	dirs  map[string]bool
+	files map[string][]byte
+}
+
+// NewMemFS returns an empty in-memory WriteFS
+func NewMemFS() *MemFS {
+	return &MemFS{
+		dirs:  make(map[string]bool),
+		files: make(map[string][]byte),
+	}
+}
+
+// MkdirAll records the directory name and its parents
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := path.Clean(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+		}
+		m.dirs[dir] = true
+	}
+	return nil
+}
+
+// WriteFile stores a copy of data as the file name
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = path.Clean(name)
+	if m.dirs[name] {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// ReadFile returns the content of the file name
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.files[path.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Files returns the sorted paths of all files
+func (m *MemFS) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ============================================================================
+// Archives
+// ============================================================================
+
+// ArchiveFS streams generated files into an archive. Close must be called
+// to flush the archive; it does not close the underlying writer.
+type ArchiveFS interface {
+	WriteFS
+	io.Closer
+}
+
+// CheckArchiveName reports an error unless name ends in .tar.gz, .tgz or
+// .zip
+func CheckArchiveName(name string) error {
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported archive format '%s' (use .tar.gz, .tgz or .zip)", name)
+}
+
+// NewArchiveFS returns an ArchiveFS writing to w in the format implied by
+// name, which must end in .tar.gz, .tgz or .zip
+func NewArchiveFS(name string, w io.Writer) (ArchiveFS, error) {
+	if err := CheckArchiveName(name); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, ".zip") {
+		return NewZipFS(w), nil
+	}
+	return NewTarGzFS(w), nil
+}
+
+// archiveDirs tracks directories already written to an archive
+type archiveDirs map[string]bool
+
+// missing returns name and its parents that were not written yet, parents first
+func (a archiveDirs) missing(name string) []string {
+	var dirs []string
+	for dir := path.Clean(name); dir != "." && dir != "/" && !a[dir]; dir = path.Dir(dir) {
+		a[dir] = true
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// TarGzFS writes a gzip-compressed tar archive
+type TarGzFS struct {
+	gz    *gzip.Writer
+	tw    *tar.Writer
+	dirs  archiveDirs
+	mtime time.Time
+}
+
+// NewTarGzFS returns an ArchiveFS writing a .tar.gz stream to w
+func NewTarGzFS(w io.Writer) *TarGzFS {
+	gz := gzip.NewWriter(w)
+	return &TarGzFS{
+		gz:    gz,
+		tw:    tar.NewWriter(gz),
+		dirs:  make(archiveDirs),
+		mtime: time.Now(),
+	}
+}
+
+// MkdirAll adds directory entries for name and its parents
+func (t *TarGzFS) MkdirAll(name string, perm fs.FileMode) error {
+	for _, dir := range t.dirs.missing(name) {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     dir + "/",
+			Mode:     int64(perm.Perm()),
+			ModTime:  t.mtime,
+		}
+		if err := t.tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile adds the file name to the archive
+func (t *TarGzFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := t.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Clean(name),
+		Mode:     int64(perm.Perm()),
+		Size:     int64(len(data)),
+		ModTime:  t.mtime,
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(data)
+	return err
+}
+
+// Close flushes the tar and gzip streams
+func (t *TarGzFS) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+// ZipFS writes a zip archive
+type ZipFS struct {
+	zw    *zip.Writer
+	dirs  archiveDirs
+	mtime time.Time
+}
+
+// NewZipFS returns an ArchiveFS writing a zip archive to w
+func NewZipFS(w io.Writer) *ZipFS {
+	return &ZipFS{
+		zw:    zip.NewWriter(w),
+		dirs:  make(archiveDirs),
+		mtime: time.Now(),
+	}
+}
+
+// MkdirAll adds directory entries for name and its parents
+func (z *ZipFS) MkdirAll(name string, perm fs.FileMode) error {
+	for _, dir := range z.dirs.missing(name) {
+		hdr := &zip.FileHeader{Name: dir + "/", Modified: z.mtime}
+		hdr.SetMode(fs.ModeDir | perm.Perm())
+		if _, err := z.zw.CreateHeader(hdr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile adds the file name to the archive
+func (z *ZipFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := z.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	hdr := &zip.FileHeader{Name: path.Clean(name), Method: zip.Deflate, Modified: z.mtime}
+	hdr.SetMode(perm.Perm())
+	w, err := z.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Close writes the zip central directory
+func (z *ZipFS) Close() error {
+	return z.zw.Close()
+}
@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+	if err := m.MkdirAll("a/b", 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("one")
+	if err := m.WriteFile("a/b/../c.txt", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 'X'
+	m.WriteFile("z.txt", nil, 0644)
+
+	if got, err := m.ReadFile("a/c.txt"); err != nil || string(got) != "one" {
+		t.Errorf("ReadFile(a/c.txt) = %q, %v, want a copy of one", got, err)
+	}
+	if _, err := m.ReadFile("a/b"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile of a directory = %v, want ErrNotExist", err)
+	}
+	if err := m.WriteFile("a/b", nil, 0644); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("WriteFile over a directory = %v, want ErrExist", err)
+	}
+	if err := m.MkdirAll("a/c.txt/d", 0755); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("MkdirAll below a file = %v, want ErrExist", err)
+	}
+	if got, want := m.Files(), []string{"a/c.txt", "z.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestSubFS(t *testing.T) {
+	m := NewMemFS()
+	sub := SubFS(m, "demo")
+
+	if err := sub.MkdirAll("cmd/demo", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"main.go", "cmd/../go.mod", "./README.md"} {
+		if err := sub.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Errorf("WriteFile(%s): %v", name, err)
+		}
+	}
+	if got, want := m.Files(), []string{"demo/README.md", "demo/go.mod", "demo/main.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+
+	// Nothing is written outside of the sub-directory
+	for _, name := range []string{"../escape.txt", "cmd/../../escape.txt", "/etc/escape.txt", ".."} {
+		if err := sub.WriteFile(name, nil, 0644); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("WriteFile(%s) = %v, want ErrInvalid", name, err)
+		}
+		if err := sub.MkdirAll(name, 0755); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("MkdirAll(%s) = %v, want ErrInvalid", name, err)
+		}
+	}
+	if got := len(m.Files()); got != 3 {
+		t.Errorf("%d files written, want 3", got)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	cfg := Config{Name: "demo", ModulePath: "example.com/demo", Template: "api", IncludeDocker: true}
+	mem := generateMem(t, cfg)
+
+	tests := []struct {
+		name string
+		read func(t *testing.T, data []byte) map[string]string
+	}{
+		{"demo.tar.gz", readTarGz},
+		{"demo.zip", readZip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			archive, err := NewArchiveFS(tt.name, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := New(cfg, WithOutput(SubFS(archive, "demo"))).Generate(); err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if err := archive.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := tt.read(t, buf.Bytes())
+			for _, name := range mem.Files() {
+				content, ok := got["demo/"+name]
+				if !ok {
+					t.Errorf("%s missing from the archive", name)
+					continue
+				}
+				delete(got, "demo/"+name)
+				// The manifest records when it was written
+				if want, _ := mem.ReadFile(name); name != ProjectManifestFile && content != string(want) {
+					t.Errorf("%s differs from the MemFS output", name)
+				}
+			}
+			for name := range got {
+				if !strings.HasSuffix(name, "/") {
+					t.Errorf("unexpected file %s in the archive", name)
+				}
+			}
+			for _, dir := range []string{"demo/", "demo/cmd/", "demo/cmd/demo/"} {
+				if _, ok := got[dir]; !ok {
+					t.Errorf("directory %s missing from the archive", dir)
+				}
+			}
+		})
+	}
+}
+
+// readTarGz returns the contents of the entries of a .tar.gz archive by
+// name, checking that every directory precedes its entries
+func readTarGz(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	entries := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkArchiveEntry(t, entries, hdr.Name, hdr.FileInfo().Mode())
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[hdr.Name] = string(content)
+	}
+	return entries
+}
+
+// readZip is readTarGz for zip archives
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := make(map[string]string)
+	for _, f := range zr.File {
+		checkArchiveEntry(t, entries, f.Name, f.Mode())
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = string(content)
+	}
+	return entries
+}
+
+// checkArchiveEntry checks the mode of the entry name and that its parent
+// is already in entries
+func checkArchiveEntry(t *testing.T, entries map[string]string, name string, mode fs.FileMode) {
+	t.Helper()
+	want := fs.FileMode(0644)
+	if strings.HasSuffix(name, "/") {
+		want = fs.ModeDir | 0755
+	}
+	if mode != want {
+		t.Errorf("%s has mode %v, want %v", name, mode, want)
+	}
+
+	if parent := name[:strings.LastIndex(strings.TrimSuffix(name, "/"), "/")+1]; parent != "" {
+		if _, ok := entries[parent]; !ok {
+			t.Errorf("%s precedes its directory %s", name, parent)
+		}
+	}
+}
+
+func TestNewArchiveFS(t *testing.T) {
+	tests := []struct {
+		name string
+		want ArchiveFS
+	}{
+		{"a.tar.gz", &TarGzFS{}},
+		{"a.tgz", &TarGzFS{}},
+		{"a.zip", &ZipFS{}},
+		{"a.tar", nil},
+		{"a", nil},
+	}
+	for _, tt := range tests {
+		a, err := NewArchiveFS(tt.name, io.Discard)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("NewArchiveFS(%s) succeeded, want an error", tt.name)
+			}
+			continue
+		}
+		if reflect.TypeOf(a) != reflect.TypeOf(tt.want) {
+			t.Errorf("NewArchiveFS(%s) = %T, %v, want %T", tt.name, a, err, tt.want)
+		}
+	}
+}
@@ -2,161 +2,269 @@ package generator
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
-	"github.com/fatih/color"
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
 )
 
 // Config holds the project generation configuration
 type Config struct {
-	Name             string
-	ModulePath       string
-	Template         string
-	IncludeMakefile  bool
-	IncludeDocker    bool
-	IncludeCI        bool
-	IncludeLint      bool
-	IncludePreCommit bool
-	IncludeTests     bool
-	InitGit          bool
+	Name             string `json:"name"`
+	ModulePath       string `json:"modulePath"`
+	Template         string `json:"template"`
+	TemplateDir      string `json:"templateDir,omitempty"`
+	IncludeMakefile  bool   `json:"includeMakefile"`
+	IncludeDocker    bool   `json:"includeDocker"`
+	IncludeCI        bool   `json:"includeCI"`
+	IncludeLint      bool   `json:"includeLint"`
+	IncludePreCommit bool   `json:"includePreCommit"`
+	IncludeTests     bool   `json:"includeTests"`
+	InitGit          bool   `json:"initGit"`
+	// Tidy runs go mod tidy once the project is written
+	Tidy      bool   `json:"tidy,omitempty"`
+	License   string `json:"license,omitempty"`
+	Author    string `json:"author,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+	// VanityRepo is the repository URL behind a custom module path; when
+	// set, a vanity import page is generated
+	VanityRepo string `json:"vanityRepo,omitempty"`
+	// Variables are template variables, available to templates as .Variables
+	Variables map[string]string `json:"variables,omitempty"`
 }
 
 // Generator handles project generation
 type Generator struct {
-	config Config
-	info   func(a ...interface{}) string
+	config   Config
+	data     templateData
+	template Template
+	out      WriteFS
+	version  string
+	entries  []Entry
+	seenDirs map[string]bool
+	// seenFiles maps the files written to their entry
+	seenFiles map[string]int
+
+	report    Reporter
+	stepStart time.Time
+
+	hooks    hooks.Hooks
+	noHooks  bool
+	approve  HookApproval
+	hookRuns []HookRun
+
+	conflicts   *ConflictOptions
+	conflictLog []Conflict
+
+	offline  bool
+	proxyDir string
 }
 
-// New creates a new Generator
-func New(cfg Config) *Generator {
-	return &Generator{
+// New creates a new Generator that writes the project to a directory named
+// after the project in the current working directory, unless another output
+// is given with WithOutput
+func New(cfg Config, opts ...Option) *Generator {
+	g := &Generator{
 		config: cfg,
-		info:   color.New(color.FgCyan).SprintFunc(),
+		data:   newTemplateData(cfg, nil),
+		out:    NewOSFS(cfg.Name),
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
-// Generate creates the project
-func (g *Generator) Generate() error {
-	// Create base directories
-	if err := g.createDirectories(); err != nil {
-		return err
-	}
+// StepError reports which generation step failed
+type StepError struct {
+	Step string
+	Err  error
+}
 
-	// Create go.mod
-	if err := g.createGoMod(); err != nil {
-		return err
-	}
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
 
-	// Create template-specific files
-	if err := g.createTemplateFiles(); err != nil {
-		return err
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// step is a single unit of work in Generate
+type step struct {
+	name    string
+	enabled bool
+	run     func() error
+}
+
+// steps returns the generation steps in the order they run. The template
+// files are written first so they take precedence over the shared files.
+func (g *Generator) steps() []step {
+	return []step{
+		{"create directories", true, g.createDirectories},
+		{"create template files", true, g.createTemplateFiles},
+		{"create go.mod", true, g.createGoMod},
+		{"create .gitignore", true, g.createGitignore},
+		{"create Makefile", g.config.IncludeMakefile, g.createMakefile},
+		{"create Docker files", g.config.IncludeDocker, g.createDockerFiles},
+		{"create CI workflow", g.config.IncludeCI, g.createCIWorkflow},
+		{"create linter config", g.config.IncludeLint, g.createLintConfig},
+		{"create pre-commit config", g.config.IncludePreCommit, g.createPreCommitConfig},
+		{"create README", true, g.createReadme},
+		{"create vanity import page", g.config.VanityRepo != "", g.createVanityPage},
+		{"tidy go.mod", g.config.Tidy, g.tidyModule},
+		{"write manifest", true, g.writeManifest},
+		{"initialize git repository", g.config.InitGit, g.initGit},
 	}
+}
 
-	// Create .gitignore
-	if err := g.createGitignore(); err != nil {
+// Generate creates the project. When writing to the operating system the
+// project is built in a staging directory next to the target and only
+// moved into place once every step succeeded, unless the directory exists
+// and WithConflicts allows writing into it. Hooks only run when writing to
+// the operating system: pre hooks in the staging directory, or the
+// existing directory, before the first step, post hooks in the project
+// directory once it is complete.
+func (g *Generator) Generate() error {
+	// Resolve the template and approve its hooks before touching disk
+	if err := ValidateModulePath(g.config.ModulePath); err != nil {
 		return err
 	}
-
-	// Create DevOps files if requested
-	if g.config.IncludeMakefile {
-		if err := g.createMakefile(); err != nil {
+	if g.config.VanityRepo != "" {
+		if err := checkVanityRepo(g.config.VanityRepo); err != nil {
 			return err
 		}
 	}
-
-	if g.config.IncludeDocker {
-		if err := g.createDockerFiles(); err != nil {
+	if err := g.resolveTemplate(); err != nil {
+		return err
+	}
+	if g.config.GoVersion != "" {
+		if err := checkGoVersion(g.template, g.config.GoVersion); err != nil {
 			return err
 		}
 	}
-
-	if g.config.IncludeCI {
-		if err := g.createCIWorkflow(); err != nil {
+	if g.conflicts != nil {
+		if err := g.conflicts.Check(); err != nil {
 			return err
 		}
 	}
 
-	// Create quality files if requested
-	if g.config.IncludeLint {
-		if err := g.createLintConfig(); err != nil {
+	if o, ok := g.out.(*OSFS); ok {
+		h, err := g.projectHooks()
+		if err != nil {
 			return err
 		}
+		if info, err := os.Stat(o.Root); err == nil && info.IsDir() && g.conflicts != nil {
+			return g.generateInPlace(o, h)
+		}
+		return g.generateStaged(o, h)
+	}
+	if h := hooks.Around(g.hooks, TemplateHooks(g.template)); !g.noHooks && !h.Empty() {
+		g.warn("hooks only run when the project is written to a directory; skipping them")
 	}
+	return g.runSteps()
+}
 
-	if g.config.IncludePreCommit {
-		if err := g.createPreCommitConfig(); err != nil {
-			return err
+func (g *Generator) runSteps() error {
+	for _, s := range g.steps() {
+		if !s.enabled {
+			continue
+		}
+		err := s.run()
+		g.endStep(err)
+		if err != nil {
+			return &StepError{Step: s.name, Err: err}
 		}
 	}
+	return nil
+}
 
-	// Create README
-	if err := g.createReadme(); err != nil {
-		return err
+// renderMemory runs all steps against an in-memory output and returns the
+// entries they produced, leaving the configured output untouched
+func (g *Generator) renderMemory() ([]Entry, error) {
+	out, report := g.out, g.report
+	g.out, g.report = NewMemFS(), nil
+	err := g.runSteps()
+	g.out, g.report = out, report
+
+	rendered := g.entries
+	g.entries, g.seenDirs, g.seenFiles = nil, nil, nil
+	return rendered, err
+}
+
+// generateStaged runs the pre hooks and all steps in a hidden staging
+// directory next to o.Root, renames the result to o.Root on success and
+// runs the post hooks there
+func (g *Generator) generateStaged(o *OSFS, h hooks.Hooks) (err error) {
+	target := filepath.Clean(o.Root)
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("directory '%s' already exists", target)
 	}
 
-	// Initialize git if requested
-	if g.config.InitGit {
-		if err := g.initGit(); err != nil {
-			return err
+	staging, err := os.MkdirTemp(filepath.Dir(target), "."+filepath.Base(target)+".goscaffold-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	if err := os.Chmod(staging, 0755); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	defer func() {
+		g.out = o
+		if rmErr := os.RemoveAll(staging); rmErr != nil && err == nil {
+			err = fmt.Errorf("failed to remove staging directory %s: %w", staging, rmErr)
 		}
+	}()
+
+	g.out = NewOSFS(staging)
+	if err := g.runHooks(h, hooks.Pre, staging); err != nil {
+		return err
+	}
+	if err := g.runSteps(); err != nil {
+		return err
 	}
 
+	if err := os.Rename(staging, target); err != nil {
+		return &StepError{Step: "move project into place", Err: err}
+	}
+	g.out = o
+	if err := g.runHooks(h, hooks.Post, target); err != nil {
+		return fmt.Errorf("%w (the project was created in %s)", err, target)
+	}
 	return nil
 }
 
-func (g *Generator) createDirectories() error {
-	fmt.Printf("  %s Creating directories...\n", g.info("→"))
-
-	dirs := []string{
-		g.config.Name,
+// generateInPlace runs the hooks and all steps in the existing directory
+// o.Root. Unlike generateStaged, files written before a failure are left
+// in place.
+func (g *Generator) generateInPlace(o *OSFS, h hooks.Hooks) error {
+	if err := g.runHooks(h, hooks.Pre, o.Root); err != nil {
+		return err
 	}
+	if err := g.runSteps(); err != nil {
+		return err
+	}
+	return g.runHooks(h, hooks.Post, o.Root)
+}
+
+func (g *Generator) createDirectories() error {
+	g.step("Creating directories...")
 
 	// Add template-specific directories
-	switch g.config.Template {
-	case "basic":
-		// No additional directories needed
-	case "cli":
-		dirs = append(dirs,
-			filepath.Join(g.config.Name, "cmd", g.config.Name),
-			filepath.Join(g.config.Name, "internal"),
-		)
-	case "api":
-		dirs = append(dirs,
-			filepath.Join(g.config.Name, "cmd", g.config.Name),
-			filepath.Join(g.config.Name, "internal", "handler"),
-			filepath.Join(g.config.Name, "internal", "middleware"),
-			filepath.Join(g.config.Name, "internal", "router"),
-			filepath.Join(g.config.Name, "pkg"),
-		)
-	case "grpc":
-		dirs = append(dirs,
-			filepath.Join(g.config.Name, "cmd", g.config.Name),
-			filepath.Join(g.config.Name, "internal", "server"),
-			filepath.Join(g.config.Name, "proto"),
-			filepath.Join(g.config.Name, "pkg"),
-		)
-	case "library":
-		dirs = append(dirs,
-			filepath.Join(g.config.Name, "pkg", g.config.Name),
-			filepath.Join(g.config.Name, "examples"),
-		)
-	default:
-		dirs = append(dirs,
-			filepath.Join(g.config.Name, "cmd"),
-			filepath.Join(g.config.Name, "internal"),
-			filepath.Join(g.config.Name, "pkg"),
-		)
+	dirs, err := newPathSelector(g.template, g.data).templateDirs(g.template)
+	if err != nil {
+		return err
 	}
 
 	// Add CI directory if needed
 	if g.config.IncludeCI {
-		dirs = append(dirs, filepath.Join(g.config.Name, ".github", "workflows"))
+		dirs = append(dirs, ".github/workflows")
 	}
 
 	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
+		if err := g.mkdir(dir); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
@@ -165,80 +273,77 @@ func (g *Generator) createDirectories() error {
 }
 
 func (g *Generator) createGoMod() error {
-	fmt.Printf("  %s Creating go.mod...\n", g.info("→"))
-
-	content := fmt.Sprintf(`module %s
-
-go 1.21
-`, g.config.ModulePath)
+	g.step("Creating go.mod...")
 
-	return writeFile(filepath.Join(g.config.Name, "go.mod"), content)
+	return g.renderFile("common/go.mod.tmpl", "go.mod")
 }
 
 func (g *Generator) createTemplateFiles() error {
-	fmt.Printf("  %s Creating template files...\n", g.info("→"))
+	g.step("Creating template files...")
 
-	switch g.config.Template {
-	case "basic":
-		return g.createBasicTemplate()
-	case "cli":
-		return g.createCLITemplate()
-	case "api":
-		return g.createAPITemplate()
-	case "grpc":
-		return g.createGRPCTemplate()
-	case "library":
-		return g.createLibraryTemplate()
-	default:
-		return g.createBasicTemplate()
+	files, err := newPathSelector(g.template, g.data).templateFiles(g.template)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := g.writeTemplateFile(f); err != nil {
+			return err
+		}
 	}
-}
-
-func (g *Generator) createGitignore() error {
-	content := `# Binaries
-bin/
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-
-# Test binary
-*.test
-
-# Output of go coverage
-*.out
 
-# Dependency directories
-vendor/
+	return nil
+}
 
-# IDE
-.idea/
-.vscode/
-*.swp
-*.swo
+// writeTemplateFile renders or copies a single template file
+func (g *Generator) writeTemplateFile(f File) error {
+	if f.Raw {
+		content, err := fs.ReadFile(f.FS, f.Source)
+		if err != nil {
+			return fmt.Errorf("failed to read template file %s: %w", f.Source, err)
+		}
+		return g.writeFile(f.Path, string(content))
+	}
+	return g.renderFSFile(f.FS, f.Source, f.Path)
+}
 
-# OS
-.DS_Store
-Thumbs.db
+// resolveTemplate looks up the configured template in the registry,
+// falling back to Config.TemplateDir and the template search path
+func (g *Generator) resolveTemplate() error {
+	t, err := ResolveTemplate(g.config.Template, g.config.TemplateDir)
+	if err != nil {
+		return err
+	}
 
-# Environment
-.env
-.env.local
+	vars, err := ResolveVariables(t, g.config, nil)
+	if err != nil {
+		return err
+	}
 
-# Build
-dist/
+	g.template = t
+	g.config.Template = t.Name()
+	g.config.Variables = vars
+	g.data = newTemplateData(g.config, t)
+	if g.data.Requires, err = TemplateRequirements(t); err != nil {
+		return err
+	}
+	return nil
+}
 
-# Logs
-*.log
-`
-	return writeFile(filepath.Join(g.config.Name, ".gitignore"), content)
+func (g *Generator) createGitignore() error {
+	return g.renderFile("common/gitignore.tmpl", ".gitignore")
 }
 
 func (g *Generator) initGit() error {
-	fmt.Printf("  %s Initializing git repository...\n", g.info("→"))
+	dir, ok := g.diskDir()
+	if !ok {
+		// Nothing on disk to initialize, e.g. when writing an archive
+		g.warn("git init only runs when the project is written to a directory; skipping it")
+		return nil
+	}
+
+	g.step("Initializing git repository...")
 
 	cmd := exec.Command("git", "init")
-	cmd.Dir = g.config.Name
+	cmd.Dir = dir
 	return cmd.Run()
 }
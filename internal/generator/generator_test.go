@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+)
+
+func TestTemplateFilesWinOverSharedFiles(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		"template.yaml":        "name: own\n",
+		"files/main.go":        "package main\n\nfunc main() {}\n",
+		"files/README.md.tmpl": "# {{.Name}} README\n",
+		"files/.gitignore":     "/own\n",
+		"files/Dockerfile":     "FROM scratch\n",
+	})
+	cfg := Config{Name: "demo", ModulePath: "example.com/demo", TemplateDir: dir, IncludeDocker: true}
+
+	out := NewMemFS()
+	g := New(cfg, WithOutput(out))
+	if err := g.Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want := map[string]string{
+		"README.md":  "# demo README\n",
+		".gitignore": "/own\n",
+		"Dockerfile": "FROM scratch\n",
+	}
+	for name, content := range want {
+		got, err := out.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", name, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want the template's %q", name, got, content)
+		}
+	}
+	// Shared files the template does not supply are still written
+	if _, err := out.ReadFile("docker-compose.yml"); err != nil {
+		t.Errorf("docker-compose.yml not written: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range g.Entries() {
+		if seen[e.Path] {
+			t.Errorf("Entries lists %s twice", e.Path)
+		}
+		seen[e.Path] = true
+	}
+}
+
+func TestWriteFileReplacesEntry(t *testing.T) {
+	g := New(Config{Name: "demo"}, WithOutput(NewMemFS()))
+	for _, content := range []string{"one\n", "two\n"} {
+		if err := g.writeFile("dir/file.txt", content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var files []string
+	for _, e := range g.Entries() {
+		if !e.Dir {
+			files = append(files, e.Path+"="+strings.TrimSpace(string(e.Content)))
+		}
+	}
+	if got := strings.Join(files, ","); got != "dir/file.txt=two" {
+		t.Errorf("file entries = %s, want dir/file.txt=two", got)
+	}
+}
+
+// failingTemplate returns a template directory whose last file fails to
+// render, after other files were written
+func failingTemplate(t *testing.T) string {
+	return writeTemplateDir(t, map[string]string{
+		"template.yaml":        "name: failing\n",
+		"files/main.go":        "package main\n\nfunc main() {}\n",
+		"files/zz.go.tmpl":     "package main\n\n// {{.NoSuchField}}\n",
+		"files/internal/.keep": "",
+	})
+}
+
+// dirNames returns the names in dir
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestGenerateRollsBack(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		opts    []Option
+		wantErr string
+	}{
+		{"failing step", Config{TemplateDir: failingTemplate(t)}, nil, "NoSuchField"},
+		{"failing pre hook", Config{Template: "basic"}, []Option{WithHooks(hooks.Hooks{Pre: []hooks.Hook{{Run: "exit 1"}}})}, "run pre hook"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			cfg := tt.cfg
+			cfg.Name, cfg.ModulePath = "demo", "example.com/demo"
+
+			opts := append([]Option{WithOutput(NewOSFS(filepath.Join(parent, "demo")))}, tt.opts...)
+			if err := New(cfg, opts...).Generate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Generate = %v, want an error containing %q", err, tt.wantErr)
+			}
+			// Neither the project nor the staging directory is left behind
+			if names := dirNames(t, parent); len(names) != 0 {
+				t.Errorf("%s holds %v after a failure, want nothing", parent, names)
+			}
+		})
+	}
+}
+
+func TestGenerateRefusesExistingDir(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "demo")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	g := New(Config{Name: "demo", ModulePath: "example.com/demo", Template: "basic"}, WithOutput(NewOSFS(target)))
+	if err := g.Generate(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Generate = %v, want an error about the existing directory", err)
+	}
+	if names := dirNames(t, target); len(names) != 0 {
+		t.Errorf("%s holds %v, want it untouched", target, names)
+	}
+}
+
+func TestGenerateInPlace(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "demo")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"main.go": "package main\n", "notes.txt": "keep\n"} {
+		if err := os.WriteFile(filepath.Join(target, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Files written before a failure stay, unlike with staged generation
+	cfg := Config{Name: "demo", ModulePath: "example.com/demo", TemplateDir: failingTemplate(t)}
+	g := New(cfg, WithOutput(NewOSFS(target)), WithConflicts(ConflictOptions{Default: ConflictSkip}))
+	if err := g.Generate(); err == nil || !strings.Contains(err.Error(), "NoSuchField") {
+		t.Fatalf("Generate = %v, want the render error", err)
+	}
+	want := []string{"internal", "main.go", "notes.txt"}
+	if names := dirNames(t, target); strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("%s holds %v, want %v", target, names, want)
+	}
+	if got, _ := os.ReadFile(filepath.Join(target, "main.go")); string(got) != "package main\n" {
+		t.Errorf("main.go = %q, want the existing file kept", got)
+	}
+	if c := g.Conflicts(); len(c) != 1 || c[0].Path != "main.go" || c[0].Policy != ConflictSkip {
+		t.Errorf("Conflicts() = %+v, want main.go skipped", c)
+	}
+}
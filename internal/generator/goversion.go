@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"fmt"
+	"go/version"
+	"os/exec"
+	"strings"
+)
+
+// defaultGoVersion is used when Config.GoVersion is empty, as in the
+// manifests of projects generated before the Go version was configurable
+const defaultGoVersion = "1.21"
+
+// toolchainSince is the first Go version that understands the toolchain
+// directive in go.mod
+const toolchainSince = "go1.21"
+
+// ParseGoVersion validates a Go version such as 1.22, 1.22.5 or go1.22.5
+// and returns it without the go prefix
+func ParseGoVersion(s string) (string, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "go")
+	if !version.IsValid("go" + v) {
+		return "", fmt.Errorf("invalid Go version %q: want a release such as 1.22 or 1.22.5", s)
+	}
+	return v, nil
+}
+
+// DetectGoVersion returns the version of the go command on the host, as
+// reported by go env GOVERSION, or a fallback if there is no usable one
+func DetectGoVersion() string {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return defaultGoVersion
+	}
+	// Experiments are listed after the version, e.g. "go1.22.5 X:boringcrypto"
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return defaultGoVersion
+	}
+	v, err := ParseGoVersion(fields[0])
+	if err != nil {
+		// Development builds have no release version
+		return defaultGoVersion
+	}
+	return v
+}
+
+// goLanguage returns the language version of v, e.g. 1.22 for 1.22.5
+func goLanguage(v string) string {
+	if lang := version.Lang("go" + v); lang != "" {
+		return strings.TrimPrefix(lang, "go")
+	}
+	return v
+}
+
+// goToolchain returns the toolchain directive for v, or "" if v names no
+// specific release or predates the directive
+func goToolchain(v string) string {
+	if !version.IsValid("go"+v) || goLanguage(v) == v || version.Compare("go"+v, toolchainSince) < 0 {
+		return ""
+	}
+	return "go" + v
+}
+
+// goVersionProvider is implemented by templates whose code needs a
+// minimum Go version
+type goVersionProvider interface {
+	MinGoVersion() string
+}
+
+// TemplateMinGoVersion returns the oldest Go language version t can be
+// generated for, and why: the version the template declares or the newest
+// go directive of the modules it requires. It returns "" if t has no
+// minimum.
+func TemplateMinGoVersion(t Template) (minVersion, reason string) {
+	if p, ok := t.(goVersionProvider); ok && p.MinGoVersion() != "" {
+		minVersion, reason = goLanguage(p.MinGoVersion()), "template "+t.Name()
+	}
+
+	reqs, _ := TemplateRequirements(t)
+	for _, r := range reqs {
+		known, ok := knownVersions[r.Path]
+		if !ok || known.Version != r.Version || known.Go == "" {
+			continue
+		}
+		if minVersion == "" || version.Compare("go"+known.Go, "go"+minVersion) > 0 {
+			minVersion, reason = known.Go, r.Path+" "+r.Version
+		}
+	}
+	return minVersion, reason
+}
+
+// checkGoVersion reports whether v is a valid Go version that supports
+// the language features used by t
+func checkGoVersion(t Template, v string) error {
+	if _, err := ParseGoVersion(v); err != nil {
+		return err
+	}
+
+	minVersion, reason := TemplateMinGoVersion(t)
+	if minVersion != "" && version.Compare("go"+goLanguage(v), "go"+minVersion) < 0 {
+		return fmt.Errorf("template %s needs Go %s or later (required by %s), not %s", t.Name(), minVersion, reason, v)
+	}
+	return nil
+}
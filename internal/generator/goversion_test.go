@@ -0,0 +1,52 @@
+package generator
+
+import "testing"
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.22", want: "1.22"},
+		{in: "1.22.5", want: "1.22.5"},
+		{in: "go1.22.5", want: "1.22.5"},
+		{in: " go1.21 ", want: "1.21"},
+		{in: "1.23rc1", want: "1.23rc1"},
+		{in: "", wantErr: true},
+		{in: "go", wantErr: true},
+		{in: "1.x", wantErr: true},
+		{in: "latest", wantErr: true},
+		{in: "1.22.x", wantErr: true},
+		{in: "v1.22", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGoVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseGoVersion(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseGoVersion(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGoDirectives(t *testing.T) {
+	tests := []struct {
+		version, language, toolchain string
+	}{
+		{"1.22", "1.22", ""},
+		{"1.22.5", "1.22", "go1.22.5"},
+		{"1.21.0", "1.21", "go1.21.0"},
+		{"1.20.3", "1.20", ""},
+	}
+	for _, tt := range tests {
+		if got := goLanguage(tt.version); got != tt.language {
+			t.Errorf("goLanguage(%q) = %q, want %q", tt.version, got, tt.language)
+		}
+		if got := goToolchain(tt.version); got != tt.toolchain {
+			t.Errorf("goToolchain(%q) = %q, want %q", tt.version, got, tt.toolchain)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+)
+
+// hookProvider is implemented by templates that declare hooks
+type hookProvider interface {
+	Hooks() hooks.Hooks
+}
+
+// TemplateHooks returns the hooks declared by t
+func TemplateHooks(t Template) hooks.Hooks {
+	if p, ok := t.(hookProvider); ok {
+		return p.Hooks()
+	}
+	return hooks.Hooks{}
+}
+
+// HookApproval decides whether the hooks of a template that does not ship
+// with goscaffold may run; an error stops generation
+type HookApproval func(t Template, h hooks.Hooks) error
+
+// WithHooks adds hooks, e.g. from the user configuration. Their pre hooks
+// run before and their post hooks after those of the template.
+func WithHooks(h hooks.Hooks) Option {
+	return func(g *Generator) {
+		g.hooks = h
+	}
+}
+
+// WithoutHooks disables all hooks
+func WithoutHooks() Option {
+	return func(g *Generator) {
+		g.noHooks = true
+	}
+}
+
+// WithHookApproval sets the function that approves template hooks. Without
+// it, templates with hooks can only be generated WithoutHooks.
+func WithHookApproval(approve HookApproval) Option {
+	return func(g *Generator) {
+		g.approve = approve
+	}
+}
+
+// projectHooks returns the hooks to run for the project, after getting
+// the template hooks approved
+func (g *Generator) projectHooks() (hooks.Hooks, error) {
+	if g.noHooks {
+		return hooks.Hooks{}, nil
+	}
+
+	th := TemplateHooks(g.template)
+	if _, builtin := g.template.(*builtin); !builtin && !th.Empty() {
+		if g.approve == nil {
+			return hooks.Hooks{}, fmt.Errorf("template %s declares hooks that were not approved", g.template.Name())
+		}
+		if err := g.approve(g.template, th); err != nil {
+			return hooks.Hooks{}, err
+		}
+	}
+	return hooks.Around(g.hooks, th), nil
+}
+
+// HookRun records a hook considered by Generate
+type HookRun struct {
+	Stage hooks.Stage
+	Hook  hooks.Hook
+	// Skipped is set when the condition of the hook was false
+	Skipped  bool
+	Duration time.Duration
+	// Output is the end of the combined output of the hook
+	Output []byte
+	Err    error
+}
+
+// HookRuns returns the hooks considered by Generate, in the order they ran
+func (g *Generator) HookRuns() []HookRun {
+	return append([]HookRun(nil), g.hookRuns...)
+}
+
+// runHooks runs the hooks of stage in dir
+func (g *Generator) runHooks(h hooks.Hooks, stage hooks.Stage, dir string) error {
+	env := g.hookEnv(stage, dir)
+	for _, hook := range h.Stage(stage) {
+		run := HookRun{Stage: stage, Hook: hook}
+		if hook.When != "" {
+			ok, err := evalCondition(hook.When, g.data)
+			if err != nil {
+				return &StepError{Step: fmt.Sprintf("run %s hook %s", stage, hook), Err: err}
+			}
+			if !ok {
+				run.Skipped = true
+				g.hookRuns = append(g.hookRuns, run)
+				continue
+			}
+		}
+
+		g.step(fmt.Sprintf("Running %s hook: %s...", stage, hook))
+		start := time.Now()
+		if g.report == nil {
+			// Nobody sees the output, so keep it with the error
+			run.Output, run.Err = hooks.Capture(hook, dir, env)
+		} else {
+			w := &hookOutput{g: g, hook: hook.String()}
+			run.Err = hooks.Run(hook, dir, env, w, w)
+			w.flush()
+			run.Output = w.tail
+		}
+		run.Duration = time.Since(start)
+		g.hookRuns = append(g.hookRuns, run)
+		g.endStep(run.Err)
+		if run.Err != nil {
+			err := run.Err
+			if g.report != nil && len(run.Output) > 0 {
+				// The reporter may have dropped the output, e.g. with --quiet
+				err = fmt.Errorf("%w\n%s", err, run.Output)
+			}
+			return &StepError{Step: fmt.Sprintf("run %s hook %s", stage, hook), Err: err}
+		}
+	}
+	return nil
+}
+
+// hookEnv describes the project to hooks through GOSCAFFOLD_PROJECT_*
+// variables
+func (g *Generator) hookEnv(stage hooks.Stage, dir string) []string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	c := g.config
+	vars := map[string]string{
+		"DIR":        dir,
+		"NAME":       c.Name,
+		"MODULE":     c.ModulePath,
+		"TEMPLATE":   c.Template,
+		"LICENSE":    g.data.License,
+		"AUTHOR":     c.Author,
+		"GO_VERSION": g.data.GoVersion,
+		"MAKEFILE":   strconv.FormatBool(c.IncludeMakefile),
+		"DOCKER":     strconv.FormatBool(c.IncludeDocker),
+		"CI":         strconv.FormatBool(c.IncludeCI),
+		"LINT":       strconv.FormatBool(c.IncludeLint),
+		"PRECOMMIT":  strconv.FormatBool(c.IncludePreCommit),
+		"TESTS":      strconv.FormatBool(c.IncludeTests),
+		"GIT":        strconv.FormatBool(c.InitGit),
+	}
+	for name, value := range c.Variables {
+		vars["VAR_"+strings.ToUpper(name)] = value
+	}
+
+	env := []string{"GOSCAFFOLD_HOOK=" + string(stage)}
+	for name, value := range vars {
+		env = append(env, "GOSCAFFOLD_PROJECT_"+name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// maxHookOutput bounds the output of a hook kept in HookRun.Output
+const maxHookOutput = 16 << 10
+
+// hookOutput reports the output of a hook line by line and keeps its end.
+// The same hookOutput is used for stdout and stderr, so os/exec writes to
+// it from one goroutine at a time.
+type hookOutput struct {
+	g       *Generator
+	hook    string
+	partial []byte
+	tail    []byte
+}
+
+func (w *hookOutput) Write(p []byte) (int, error) {
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > maxHookOutput {
+		w.tail = w.tail[len(w.tail)-maxHookOutput:]
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	if len(w.partial) > maxHookOutput {
+		w.flush()
+	}
+	return len(p), nil
+}
+
+// flush reports the last line if it has no newline
+func (w *hookOutput) flush() {
+	if len(w.partial) > 0 {
+		w.line(w.partial)
+		w.partial = nil
+	}
+}
+
+func (w *hookOutput) line(b []byte) {
+	w.g.emit(Event{Kind: EventHookOutput, Hook: w.hook, Message: string(bytes.TrimSuffix(b, []byte("\r")))})
+}
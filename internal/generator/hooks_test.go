@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+)
+
+func TestFailingHookOutputInError(t *testing.T) {
+	// The output differs from the command, which is part of the error anyway
+	h := hooks.Hooks{Post: []hooks.Hook{{Run: "echo $GOSCAFFOLD_HOOK-out; echo $GOSCAFFOLD_PROJECT_NAME-err >&2; exit 3"}}}
+	// A reporter that drops the output, like --quiet
+	quiet := ReporterFunc(func(e Event) {})
+
+	for _, opt := range []Option{WithReporter(quiet), WithQuiet()} {
+		out := NewOSFS(filepath.Join(t.TempDir(), "demo"))
+		g := New(Config{Name: "demo", ModulePath: "example.com/demo", Template: "basic"}, WithOutput(out), WithHooks(h), opt)
+		err := g.Generate()
+		if err == nil {
+			t.Fatal("Generate succeeded, want the hook to fail")
+		}
+		for _, want := range []string{"exit status 3", "post-out", "demo-err"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not contain %q", err, want)
+			}
+		}
+	}
+}
+
+func TestTemplateHookApproval(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		"template.yaml": "name: hooked\nhooks:\n  post:\n    - touch hooked\n",
+		"files/main.go": "package main\n\nfunc main() {}\n",
+	})
+	refused := errors.New("not approved")
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+		ran     bool
+	}{
+		{name: "no approval", wantErr: "template hooked declares hooks that were not approved"},
+		{name: "refused", opts: []Option{WithHookApproval(func(Template, hooks.Hooks) error { return refused })}, wantErr: "not approved"},
+		{name: "approved", opts: []Option{WithHookApproval(func(Template, hooks.Hooks) error { return nil })}, ran: true},
+		{name: "without hooks", opts: []Option{WithoutHooks()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "demo")
+			opts := append([]Option{WithOutput(NewOSFS(target))}, tt.opts...)
+			err := New(Config{Name: "demo", ModulePath: "example.com/demo", TemplateDir: dir}, opts...).Generate()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Generate = %v, want %q", err, tt.wantErr)
+				}
+				// Nothing is written before the hooks are approved
+				if _, err := os.Stat(target); err == nil {
+					t.Errorf("%s was created", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(filepath.Join(target, "hooked")); (err == nil) != tt.ran {
+				t.Errorf("hook ran = %v, want %v", err == nil, tt.ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ProjectManifestFile records how a project was generated. It lives at the
+// project root next to go.mod.
+const ProjectManifestFile = ".goscaffold.json"
+
+// BuiltinSource is the template source recorded for built-in templates
+const BuiltinSource = "builtin"
+
+// ProjectManifest records how a project was generated
+type ProjectManifest struct {
+	// ToolVersion is the goscaffold version that generated the project
+	ToolVersion string       `json:"goscaffoldVersion"`
+	Template    TemplateInfo `json:"template"`
+	Config      Config       `json:"config"`
+	GeneratedAt time.Time    `json:"generatedAt"`
+	UpdatedAt   time.Time    `json:"updatedAt,omitzero"`
+	// Files maps every generated file to the hash of its generated content
+	Files map[string]string `json:"files"`
+}
+
+// TemplateInfo identifies the template a project was generated from
+type TemplateInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Source is BuiltinSource or the directory of a user-supplied template
+	Source string `json:"source"`
+}
+
+// HashContent returns the hash recorded in the manifest for content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// LoadManifest reads the project manifest from the project in dir
+func LoadManifest(dir string) (*ProjectManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ProjectManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(data)
+}
+
+func parseManifest(data []byte) (*ProjectManifest, error) {
+	var m ProjectManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ProjectManifestFile, err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+	return &m, nil
+}
+
+// TemplateSource returns BuiltinSource for built-in templates and the absolute
+// directory of templates loaded from disk
+func TemplateSource(t Template) string {
+	dt, ok := t.(*DirTemplate)
+	if !ok {
+		return BuiltinSource
+	}
+	if abs, err := filepath.Abs(dt.Dir); err == nil {
+		return abs
+	}
+	return dt.Dir
+}
+
+// templateInfo describes the resolved template for the manifest
+func (g *Generator) templateInfo() TemplateInfo {
+	return TemplateInfo{
+		Name:    g.template.Name(),
+		Version: g.template.Version(),
+		Source:  TemplateSource(g.template),
+	}
+}
+
+// recordFiles adds the hash of every file written so far to m
+func (g *Generator) recordFiles(m *ProjectManifest) {
+	for _, e := range g.entries {
+		if e.Dir || e.Path == ProjectManifestFile {
+			continue
+		}
+		m.Files[e.Path] = HashContent(e.Content)
+	}
+}
+
+func (g *Generator) writeManifestFile(m *ProjectManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.writeFile(ProjectManifestFile, string(data)+"\n")
+}
+
+func (g *Generator) writeManifest() error {
+	g.step("Writing " + ProjectManifestFile + "...")
+
+	m := &ProjectManifest{
+		ToolVersion: g.version,
+		Template:    g.templateInfo(),
+		Config:      g.config,
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Files:       make(map[string]string),
+	}
+	if m.Config.TemplateDir != "" {
+		m.Config.TemplateDir = m.Template.Source
+	}
+	g.recordFiles(m)
+	return g.writeManifestFile(m)
+}
+
+// updateManifest records files added to an existing project. Projects
+// without a manifest are left alone.
+func (g *Generator) updateManifest() error {
+	r, ok := g.out.(ReadFileFS)
+	if !ok {
+		return nil
+	}
+	data, err := r.ReadFile(ProjectManifestFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", ProjectManifestFile, err)
+	}
+	m, err := parseManifest(data)
+	if err != nil {
+		return err
+	}
+
+	m.Config = g.config
+	m.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	g.recordFiles(m)
+	return g.writeManifestFile(m)
+}
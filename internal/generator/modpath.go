@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// DefaultHost is the code host module paths are built for unless another
+// one is configured
+const DefaultHost = "github.com"
+
+// HostModulePath returns the module path of the repository name owned by
+// owner on host, e.g. gitlab.com/group/name
+func HostModulePath(host, owner, name string) string {
+	if host == "" {
+		host = DefaultHost
+	}
+	return strings.Trim(host, "/") + "/" + strings.Trim(owner, "/") + "/" + name
+}
+
+// ValidateModulePath checks p against the rules of the go command. Paths
+// whose first element is a host name must be valid module paths, so they
+// can be fetched; others, like "myapp", only need to be valid import paths.
+func ValidateModulePath(p string) error {
+	first, _, _ := strings.Cut(p, "/")
+	check := module.CheckImportPath
+	if strings.Contains(first, ".") {
+		check = module.CheckPath
+	}
+	if err := check(p); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+	return nil
+}
+
+// splitMajor splits a module path into the path without its major version
+// suffix, e.g. /v2 or .v2 for gopkg.in, and the major version, or 0
+func splitMajor(p string) (string, int) {
+	prefix, pathMajor, ok := module.SplitPathVersion(p)
+	if !ok || pathMajor == "" {
+		return p, 0
+	}
+	major, err := strconv.Atoi(strings.TrimPrefix(module.PathMajorPrefix(pathMajor), "v"))
+	if err != nil {
+		return p, 0
+	}
+	return prefix, major
+}
+
+// checkVanityRepo validates the repository URL of a vanity import page
+func checkVanityRepo(repo string) error {
+	u, err := url.Parse(repo)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid repository URL %q for the vanity import page", repo)
+	}
+	return nil
+}
+
+// createVanityPage writes the page that makes go get resolve the custom
+// module path to its repository
+func (g *Generator) createVanityPage() error {
+	g.step("Creating vanity import page...")
+
+	return g.renderFile("common/vanity.html.tmpl", "vanity/index.html")
+}
@@ -0,0 +1,61 @@
+package generator
+
+import "testing"
+
+func TestValidateModulePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "github.com/acme/demo"},
+		{path: "example.com/demo/v2"},
+		{path: "gopkg.in/yaml.v3"},
+		{path: "myapp"},
+		{path: "internal/tools"},
+		{path: "", wantErr: true},
+		{path: "github.com/acme/demo/", wantErr: true},
+		{path: "github.com/acme/my app", wantErr: true},
+		{path: "Example.com/demo", wantErr: true},
+		{path: "example.com/demo/v1", wantErr: true},
+		{path: "-demo", wantErr: true},
+		{path: "../demo", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := ValidateModulePath(tt.path); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateModulePath(%q) = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSplitMajor(t *testing.T) {
+	tests := []struct {
+		path  string
+		root  string
+		major int
+	}{
+		{"github.com/acme/demo", "github.com/acme/demo", 0},
+		{"github.com/acme/demo/v2", "github.com/acme/demo", 2},
+		{"gopkg.in/yaml.v3", "gopkg.in/yaml", 3},
+		{"myapp", "myapp", 0},
+	}
+	for _, tt := range tests {
+		root, major := splitMajor(tt.path)
+		if root != tt.root || major != tt.major {
+			t.Errorf("splitMajor(%q) = %q, %d, want %q, %d", tt.path, root, major, tt.root, tt.major)
+		}
+	}
+}
+
+func TestHostModulePath(t *testing.T) {
+	tests := []struct {
+		host, owner, name, want string
+	}{
+		{"", "acme", "demo", "github.com/acme/demo"},
+		{"gitlab.com/", "/group/sub/", "demo", "gitlab.com/group/sub/demo"},
+	}
+	for _, tt := range tests {
+		if got := HostModulePath(tt.host, tt.owner, tt.name); got != tt.want {
+			t.Errorf("HostModulePath(%q, %q, %q) = %q, want %q", tt.host, tt.owner, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"path"
+)
+
+// Entry is a directory or file produced by Generate
+type Entry struct {
+	// Path is slash-separated and relative to the project root
+	Path    string
+	Dir     bool
+	Content []byte
+}
+
+// Entries returns every directory and file produced by Generate, in the
+// order they were created
+func (g *Generator) Entries() []Entry {
+	return append([]Entry(nil), g.entries...)
+}
+
+// Dir returns the directory the project is written to, or false when the
+// output is not the operating system
+func (g *Generator) Dir() (string, bool) {
+	return g.diskDir()
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithOutput makes the generator write the project to fsys, whose root
+// becomes the project root
+func WithOutput(fsys WriteFS) Option {
+	return func(g *Generator) {
+		g.out = fsys
+	}
+}
+
+// WithToolVersion records the goscaffold version in the project manifest
+func WithToolVersion(version string) Option {
+	return func(g *Generator) {
+		g.version = version
+	}
+}
+
+// WithQuiet drops the events of the generator, undoing WithReporter
+func WithQuiet() Option {
+	return func(g *Generator) {
+		g.report = nil
+	}
+}
+
+// diskDir returns the directory the project is written to when the
+// output is the operating system
+func (g *Generator) diskDir() (string, bool) {
+	o, ok := g.out.(*OSFS)
+	if !ok {
+		return "", false
+	}
+	return o.Root, true
+}
+
+// mkdir creates the slash-separated dir and its parents, recording every
+// new directory
+func (g *Generator) mkdir(dir string) error {
+	if dir == "." || g.seenDirs[dir] {
+		return nil
+	}
+	if err := g.mkdir(path.Dir(dir)); err != nil {
+		return err
+	}
+
+	if err := g.out.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if g.seenDirs == nil {
+		g.seenDirs = make(map[string]bool)
+	}
+	g.seenDirs[dir] = true
+	g.entries = append(g.entries, Entry{Path: dir, Dir: true})
+	g.emit(Event{Kind: EventFileWritten, Path: dir, Dir: true})
+	return nil
+}
+
+// produced reports whether the slash-separated file name was written
+func (g *Generator) produced(name string) bool {
+	_, ok := g.seenFiles[name]
+	return ok
+}
+
+// writeFile writes content to the slash-separated name, creating parent
+// directories if needed. An existing file is treated according to the
+// conflict options; the entry records the generated content either way.
+func (g *Generator) writeFile(name, content string) error {
+	if err := g.mkdir(path.Dir(name)); err != nil {
+		return err
+	}
+
+	data, write, err := g.resolveConflict(name, []byte(content))
+	if err != nil {
+		return err
+	}
+	if write {
+		if err := g.out.WriteFile(name, data, 0644); err != nil {
+			return err
+		}
+		g.emit(Event{Kind: EventFileWritten, Path: name, Size: len(data)})
+	}
+
+	g.record(name, []byte(content))
+	return nil
+}
+
+// record adds the file name with content to the entries, replacing its
+// entry if it was recorded before
+func (g *Generator) record(name string, content []byte) {
+	if i, ok := g.seenFiles[name]; ok {
+		g.entries[i].Content = content
+		return
+	}
+	if g.seenFiles == nil {
+		g.seenFiles = make(map[string]int)
+	}
+	g.seenFiles[name] = len(g.entries)
+	g.entries = append(g.entries, Entry{Path: name, Content: content})
+}
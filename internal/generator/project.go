@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// DetectProject inspects the Go module in dir and returns the Config that
+// best describes it. Projects generated by goscaffold return the Config
+// recorded in their manifest; otherwise the module path is read from go.mod
+// and the template and components are inferred from the directory layout.
+func DetectProject(dir string) (Config, error) {
+	if m, err := LoadManifest(dir); err == nil {
+		return m.Config, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Config{}, fmt.Errorf("no go.mod found in %s", dir)
+		}
+		return Config{}, err
+	}
+
+	modPath := modfile.ModulePath(data)
+	if modPath == "" {
+		return Config{}, fmt.Errorf("go.mod in %s has no module directive", dir)
+	}
+
+	// Drop a major version suffix such as /v2 from the project name
+	name := path.Base(modPath)
+	if prefix, _, ok := module.SplitPathVersion(modPath); ok && prefix != "" {
+		name = path.Base(prefix)
+	}
+	name = detectName(dir, name)
+
+	cfg := Config{
+		Name:       name,
+		ModulePath: modPath,
+		Template:   detectTemplate(dir, name),
+		GoVersion:  detectGoVersion(data),
+	}
+	detectComponents(dir, &cfg)
+	return cfg, nil
+}
+
+// detectGoVersion returns the Go release named by the toolchain or go
+// directive of go.mod, or "" if there is none
+func detectGoVersion(data []byte) string {
+	f, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return ""
+	}
+	if f.Toolchain != nil {
+		if v, err := ParseGoVersion(f.Toolchain.Name); err == nil {
+			return v
+		}
+	}
+	if f.Go != nil {
+		return f.Go.Version
+	}
+	return ""
+}
+
+// detectComponents enables the components whose main file exists in dir
+func detectComponents(dir string, cfg *Config) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		return err == nil
+	}
+
+	cfg.IncludeMakefile = exists("Makefile")
+	cfg.IncludeDocker = exists("Dockerfile")
+	cfg.IncludeCI = exists(".github/workflows/ci.yml")
+	cfg.IncludeLint = exists(".golangci.yml")
+	cfg.IncludePreCommit = exists(".pre-commit-config.yaml")
+}
+
+// detectName prefers the single directory under cmd/ over the module name
+func detectName(dir, name string) string {
+	if isDir(filepath.Join(dir, "cmd", name)) {
+		return name
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cmd"))
+	if err != nil {
+		return name
+	}
+	var cmds []string
+	for _, e := range entries {
+		if e.IsDir() {
+			cmds = append(cmds, e.Name())
+		}
+	}
+	if len(cmds) == 1 {
+		return cmds[0]
+	}
+	return name
+}
+
+// detectTemplate maps the layout of dir to the closest built-in template
+func detectTemplate(dir, name string) string {
+	has := func(elem ...string) bool {
+		return isDir(filepath.Join(append([]string{dir}, elem...)...))
+	}
+
+	switch {
+	case has("cmd", name) && has("internal", "router"):
+		return apiTemplate.name
+	case has("cmd", name) && (has("internal", "server") || has("proto")):
+		return grpcTemplate.name
+	case has("cmd", name):
+		return cliTemplate.name
+	case has("pkg", name):
+		return libraryTemplate.name
+	default:
+		return basicTemplate.name
+	}
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
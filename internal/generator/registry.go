@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Template describes a project template
+type Template interface {
+	// Name is the identifier used with --template
+	Name() string
+	// Description is a one-line summary shown in prompts and help
+	Description() string
+	// Version identifies the revision of the template content
+	Version() string
+	// Directories lists the directories to create, relative to the project
+	// root. Like file paths, they may contain template actions and are
+	// subject to the template's rules, if any.
+	Directories(cfg Config) []string
+	// Files lists the files to create
+	Files(cfg Config) []File
+	// Entrypoint is the package path of the main package, e.g. "./cmd/app"
+	Entrypoint(cfg Config) string
+	// RunCommand is the command that runs the generated project
+	RunCommand(cfg Config) string
+	// Readme is template text that may define the "description" and
+	// "usage" blocks of the generated README
+	Readme(cfg Config) string
+}
+
+// File is a single file produced by a template
+type File struct {
+	// Path is the slash-separated destination relative to the project root.
+	// It may contain template actions, e.g. cmd/{{.Name}}/main.go.
+	Path string
+	// FS holds the file body
+	FS fs.FS
+	// Source is the path of the body inside FS
+	Source string
+	// Raw copies the body without rendering it
+	Raw bool
+}
+
+// Registry holds the templates known to goscaffold
+type Registry struct {
+	mu        sync.RWMutex
+	templates []Template
+	byName    map[string]Template
+}
+
+var defaultRegistry = &Registry{}
+
+// Register adds t to the registry
+func (r *Registry) Register(t Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byName == nil {
+		r.byName = make(map[string]Template)
+	}
+	if _, ok := r.byName[t.Name()]; ok {
+		return fmt.Errorf("template '%s' is already registered", t.Name())
+	}
+	r.templates = append(r.templates, t)
+	r.byName[t.Name()] = t
+	return nil
+}
+
+// Lookup returns the template registered as name
+func (r *Registry) Lookup(name string) (Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.byName[name]
+	return t, ok
+}
+
+// Templates returns all registered templates in registration order
+func (r *Registry) Templates() []Template {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Template(nil), r.templates...)
+}
+
+// Register adds t to the default registry
+func Register(t Template) error {
+	return defaultRegistry.Register(t)
+}
+
+// Lookup returns the template registered as name in the default registry
+func Lookup(name string) (Template, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// Templates returns all templates in the default registry
+func Templates() []Template {
+	return defaultRegistry.Templates()
+}
+
+// RegisterSearchPath registers every template directory found in searchPath
+// and returns the errors of those that failed to load. Templates whose name
+// is already taken are skipped; ResolveTemplate reports the load error of a
+// directory if its name is requested.
+func RegisterSearchPath(searchPath []string) []error {
+	var errs []error
+	for _, dir := range searchPath {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			candidate := filepath.Join(dir, entry.Name())
+			if _, err := os.Stat(filepath.Join(candidate, ManifestFile)); err != nil {
+				// Not a template directory
+				continue
+			}
+			t, err := LoadDirTemplate(candidate)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if _, ok := Lookup(t.Name()); ok {
+				continue
+			}
+			_ = Register(t)
+		}
+	}
+	return errs
+}
+
+// ResolveTemplate returns the template loaded from dir when set, otherwise
+// the registered template called name, falling back to the search path
+func ResolveTemplate(name, dir string) (Template, error) {
+	if dir != "" {
+		return LoadDirTemplate(dir)
+	}
+	if t, ok := Lookup(name); ok {
+		return t, nil
+	}
+	return FindDirTemplate(name, TemplateSearchPath())
+}
@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/mod/module"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// defaultLicense is the license named in generated projects unless
+// Config.License is set
+const defaultLicense = "MIT"
+
+// templateData is the data every template is rendered against
+type templateData struct {
+	Config
+	// GoVersion is the Go release used for Docker images and CI, and
+	// GoLanguage its language version, used in the go directive
+	GoVersion  string
+	GoLanguage string
+	// GoToolchain is the toolchain directive, empty if GoVersion names
+	// no specific release
+	GoToolchain string
+	Entrypoint  string
+	// ModuleRoot is the module path without its major version suffix,
+	// and MajorVersion that suffix, e.g. 2 for /v2, or 0
+	ModuleRoot   string
+	MajorVersion int
+	// Variables holds the template variables converted to their type;
+	// declared variables without a value hold the zero value of their type
+	Variables map[string]interface{}
+	// Requires lists the modules the template code imports, for go.mod
+	Requires []module.Version
+}
+
+func newTemplateData(cfg Config, t Template) templateData {
+	d := templateData{
+		Config:     cfg,
+		GoVersion:  defaultGoVersion,
+		Entrypoint: ".",
+	}
+	if cfg.GoVersion != "" {
+		d.GoVersion = strings.TrimPrefix(cfg.GoVersion, "go")
+	}
+	d.ModuleRoot, d.MajorVersion = splitMajor(cfg.ModulePath)
+	d.GoLanguage = goLanguage(d.GoVersion)
+	d.GoToolchain = goToolchain(d.GoVersion)
+	if d.License == "" {
+		d.License = defaultLicense
+	}
+	if t != nil {
+		d.Entrypoint = t.Entrypoint(cfg)
+	}
+
+	d.Variables = make(map[string]interface{})
+	for name, value := range cfg.Variables {
+		d.Variables[name] = value
+	}
+	for _, v := range TemplateVariables(t) {
+		value, ok := cfg.Variables[v.Name]
+		if !ok {
+			value = v.zero()
+		}
+		d.Variables[v.Name] = v.typed(value)
+	}
+	return d
+}
+
+// funcs returns the helper functions available inside templates
+func (d templateData) funcs() template.FuncMap {
+	return template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": title,
+		"modulePath": func(elem ...string) string {
+			return path.Join(append([]string{d.ModulePath}, elem...)...)
+		},
+		"goVersion": func() string {
+			return d.GoVersion
+		},
+	}
+}
+
+// render executes the embedded template name against data
+func (g *Generator) render(name string, data interface{}) (string, error) {
+	return g.renderFS(templateFS, path.Join("templates", name), data)
+}
+
+// renderFS executes the template stored at name in fsys against data
+func (g *Generator) renderFS(fsys fs.FS, name string, data interface{}) (string, error) {
+	text, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	return g.renderText(name, data, string(text))
+}
+
+// renderText executes the template text against data. Any extra texts are
+// parsed into the same set, so they can redefine blocks of the first one.
+func (g *Generator) renderText(name string, data interface{}, text string, extra ...string) (string, error) {
+	tmpl := template.New(path.Base(name)).
+		Funcs(g.data.funcs()).
+		Option("missingkey=error")
+	for _, t := range append([]string{text}, extra...) {
+		var err error
+		if tmpl, err = tmpl.Parse(t); err != nil {
+			return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// renderFile renders the embedded template name with the generator data
+// and writes the result to dest
+func (g *Generator) renderFile(name, dest string) error {
+	return g.renderFileWith(name, dest, g.data)
+}
+
+// renderFSFile renders the template stored at name in fsys with the
+// generator data and writes the result to dest
+func (g *Generator) renderFSFile(fsys fs.FS, name, dest string) error {
+	content, err := g.renderFS(fsys, name, g.data)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(dest, content)
+}
+
+// renderFileWith renders the embedded template name with custom data
+// and writes the result to dest, unless a template file produced dest:
+// the files of a template take precedence over the shared ones
+func (g *Generator) renderFileWith(name, dest string, data interface{}) error {
+	if g.produced(dest) {
+		return nil
+	}
+	content, err := g.render(name, data)
+	if err != nil {
+		return err
+	}
+	return g.writeFile(dest, content)
+}
+
+// title upper-cases the first letter of s
+func title(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
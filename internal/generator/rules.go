@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule makes the template files and directories matching Path conditional.
+// Rules do not apply to the files shared by all templates, which follow
+// the Include options of the Config.
+type Rule struct {
+	// Path is a slash-separated glob matched against template paths before
+	// they are rendered; "**" matches any number of directories. Everything
+	// below a matching directory is governed by the rule too.
+	Path string `yaml:"path"`
+	// When is a template pipeline, e.g. `.IncludeDocker`; matching paths
+	// are only generated when it evaluates to true
+	When string `yaml:"when"`
+}
+
+// UnmarshalYAML accepts the "<path> when <condition>" shorthand next to
+// the path/when mapping
+func (r *Rule) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		p, when, ok := strings.Cut(node.Value, " when ")
+		if !ok {
+			return fmt.Errorf("line %d: rule %q: want \"<path> when <condition>\"", node.Line, node.Value)
+		}
+		r.Path, r.When = strings.TrimSpace(p), strings.TrimSpace(when)
+		return nil
+	}
+	type plain Rule
+	return node.Decode((*plain)(r))
+}
+
+// check validates the declaration of r
+func (r Rule) check() error {
+	if r.Path == "" {
+		return fmt.Errorf("rule has no path")
+	}
+	for _, elem := range strings.Split(r.Path, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return fmt.Errorf("rule %s: invalid pattern", r.Path)
+		}
+	}
+	if r.When == "" {
+		return fmt.Errorf("rule %s has no condition", r.Path)
+	}
+	if _, err := parseCondition(r.When, templateData{}); err != nil {
+		return fmt.Errorf("rule %s: %w", r.Path, err)
+	}
+	return nil
+}
+
+// ruleProvider is implemented by templates with conditional paths
+type ruleProvider interface {
+	Rules() []Rule
+}
+
+// TemplateRules returns the rules declared by t
+func TemplateRules(t Template) []Rule {
+	if p, ok := t.(ruleProvider); ok {
+		return p.Rules()
+	}
+	return nil
+}
+
+// matchGlob reports whether the slash-separated name matches pattern
+func matchGlob(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// pathSelector decides which template paths are generated and where
+type pathSelector struct {
+	rules []Rule
+	data  templateData
+	// results caches the outcome of each rule's condition
+	results map[int]bool
+}
+
+func newPathSelector(t Template, data templateData) *pathSelector {
+	return &pathSelector{rules: TemplateRules(t), data: data, results: make(map[int]bool)}
+}
+
+// include reports whether the template path p, or one of its parent
+// directories, is excluded by a rule whose condition is false
+func (s *pathSelector) include(p string) (bool, error) {
+	for i, r := range s.rules {
+		matched := false
+		for dir := p; dir != "." && !matched; dir = path.Dir(dir) {
+			matched = matchGlob(r.Path, dir)
+		}
+		if !matched {
+			continue
+		}
+
+		ok, done := s.results[i]
+		if !done {
+			var err error
+			if ok, err = evalCondition(r.When, s.data); err != nil {
+				return false, fmt.Errorf("rule %s: %w", r.Path, err)
+			}
+			s.results[i] = ok
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// render expands the template actions in the path p, such as
+// cmd/{{.Name}}/main.go
+func (s *pathSelector) render(p string) (string, error) {
+	if !strings.Contains(p, "{{") {
+		return p, nil
+	}
+
+	tmpl, err := template.New(p).Funcs(s.data.funcs()).Option("missingkey=error").Parse(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid path template %s: %w", p, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s.data); err != nil {
+		return "", fmt.Errorf("failed to render path %s: %w", p, err)
+	}
+
+	rendered := path.Clean(buf.String())
+	for _, elem := range strings.Split(rendered, "/") {
+		if elem == ".." || elem == "" {
+			return "", fmt.Errorf("path %s renders to %q, which is outside the project", p, buf.String())
+		}
+	}
+	if rendered == "." {
+		return "", fmt.Errorf("path %s renders to an empty path", p)
+	}
+	return rendered, nil
+}
+
+// templateDirs returns the rendered directories of the template selected
+// for the configuration behind s
+func (s *pathSelector) templateDirs(t Template) ([]string, error) {
+	var dirs []string
+	for _, dir := range t.Directories(s.data.Config) {
+		ok, err := s.include(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if dir, err = s.render(dir); err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// templateFiles returns the files of t selected for the configuration
+// behind s, with their destination paths rendered
+func (s *pathSelector) templateFiles(t Template) ([]File, error) {
+	var files []File
+	for _, f := range t.Files(s.data.Config) {
+		ok, err := s.include(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if f.Path, err = s.render(f.Path); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
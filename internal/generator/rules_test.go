@@ -0,0 +1,55 @@
+package generator
+
+import "testing"
+
+func TestRuleOnSharedFile(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		"template.yaml":    "name: rules\nrules:\n  - Dockerfile when .IncludeDocker\n",
+		"files/main.go":    "package main\n\nfunc main() {}\n",
+		"files/Dockerfile": "FROM scratch\n",
+	})
+
+	tests := []struct {
+		docker bool
+		want   string
+	}{
+		{false, ""},
+		{true, "FROM scratch\n"},
+	}
+	for _, tt := range tests {
+		out := generateMem(t, Config{Name: "demo", ModulePath: "example.com/demo", TemplateDir: dir, IncludeDocker: tt.docker})
+		got, err := out.ReadFile("Dockerfile")
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("docker=%v: Dockerfile written, want none", tt.docker)
+			}
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("docker=%v: Dockerfile = %q, %v, want %q", tt.docker, got, err, tt.want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"Dockerfile", "Dockerfile", true},
+		{"Dockerfile", "deploy/Dockerfile", false},
+		{"**/Dockerfile", "deploy/Dockerfile", true},
+		{"**/Dockerfile", "Dockerfile", true},
+		{"**/*_test.go", "internal/app/app_test.go", true},
+		{"**/*_test.go", "internal/app/app.go", false},
+		{"internal/db/**", "internal/db/conn.go", true},
+		{"internal/db/**", "internal/dbx/conn.go", false},
+		{"**/.*ignore", ".gitignore", true},
+		{"**/.*ignore", "web/.dockerignore", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
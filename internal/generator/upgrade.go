@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azrakarakaya1/goscaffold/internal/diff"
+)
+
+// UpgradeAction describes what Upgrade did with a file
+type UpgradeAction string
+
+const (
+	// UpgradeUnchanged means the file already matches the current templates
+	UpgradeUnchanged UpgradeAction = "unchanged"
+	// UpgradeUpdated means an unedited file was replaced by the new output
+	UpgradeUpdated UpgradeAction = "updated"
+	// UpgradeAdded means the current templates produce a new file
+	UpgradeAdded UpgradeAction = "added"
+	// UpgradeKept means local edits were kept because the template output
+	// did not change
+	UpgradeKept UpgradeAction = "kept"
+	// UpgradeMerged means local edits and template changes were merged
+	UpgradeMerged UpgradeAction = "merged"
+	// UpgradeConflict means local edits and template changes overlap
+	UpgradeConflict UpgradeAction = "conflict"
+	// UpgradeSkipped means the file was deleted locally and stays deleted
+	UpgradeSkipped UpgradeAction = "skipped"
+)
+
+// FileUpgrade reports the outcome of Upgrade for a single file
+type FileUpgrade struct {
+	Path      string        `json:"path"`
+	Action    UpgradeAction `json:"action"`
+	Conflicts int           `json:"conflicts,omitempty"`
+	// Reject is the file the conflicting hunks were written to, if any
+	Reject string `json:"reject,omitempty"`
+}
+
+// UpgradeOptions controls Upgrade
+type UpgradeOptions struct {
+	// DryRun reports what would happen without writing anything
+	DryRun bool
+	// Reject keeps local content in conflicting regions and writes the
+	// template side of each conflict to <file>.rej instead of inserting
+	// conflict markers
+	Reject bool
+}
+
+// rejectSuffix is appended to the path of a file to store rejected hunks
+const rejectSuffix = ".rej"
+
+// Upgrade re-renders the project from the Config recorded in its manifest
+// with the current templates and merges the result into the project.
+// Files left as generated are replaced, files edited locally are merged
+// three ways against the originally generated content, and the manifest is
+// updated to the new output. The original content of an edited file is
+// recovered from git history by its recorded hash; when it cannot be found
+// every difference between the local file and the new output is treated as
+// a conflict.
+func (g *Generator) Upgrade(opts UpgradeOptions) ([]FileUpgrade, error) {
+	r, ok := g.out.(ReadFileFS)
+	if !ok {
+		return nil, fmt.Errorf("upgrade needs an output that can read existing files")
+	}
+	data, err := r.ReadFile(ProjectManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("no %s found (only projects generated by goscaffold can be upgraded): %w", ProjectManifestFile, err)
+	}
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g.config = m.Config
+	if err := g.resolveTemplate(); err != nil {
+		return nil, err
+	}
+
+	rendered, err := g.renderMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []FileUpgrade
+	files := make(map[string]string)
+	for _, e := range rendered {
+		if e.Dir || e.Path == ProjectManifestFile {
+			continue
+		}
+		files[e.Path] = HashContent(e.Content)
+
+		res, content, reject := g.upgradeFile(r, e, m.Files[e.Path], opts)
+		results = append(results, res)
+		if opts.DryRun || content == nil {
+			continue
+		}
+
+		g.step("Upgrading " + e.Path + "...")
+		err := g.writeFile(e.Path, string(content))
+		if err == nil && reject != "" {
+			err = g.writeFile(res.Reject, reject)
+		}
+		g.endStep(err)
+		if err != nil {
+			return results, err
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+
+	if opts.DryRun {
+		return results, nil
+	}
+
+	m.ToolVersion = g.version
+	m.Template = g.templateInfo()
+	m.Config = g.config
+	if m.Config.TemplateDir != "" {
+		m.Config.TemplateDir = m.Template.Source
+	}
+	m.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	m.Files = files
+	if err := g.writeManifestFile(m); err != nil {
+		return results, &StepError{Step: "update manifest", Err: err}
+	}
+	return results, nil
+}
+
+// upgradeFile decides what to do with the rendered entry e, whose content
+// was recorded under baseHash when the project was generated. It returns
+// the content to write, or nil to leave the file alone, and the rejected
+// hunks if any.
+func (g *Generator) upgradeFile(r ReadFileFS, e Entry, baseHash string, opts UpgradeOptions) (FileUpgrade, []byte, string) {
+	res := FileUpgrade{Path: e.Path}
+
+	ours, err := r.ReadFile(e.Path)
+	if err != nil {
+		if baseHash != "" {
+			res.Action = UpgradeSkipped
+			return res, nil, ""
+		}
+		res.Action = UpgradeAdded
+		return res, e.Content, ""
+	}
+
+	oursHash, theirsHash := HashContent(ours), HashContent(e.Content)
+	switch {
+	case oursHash == theirsHash, e.Path == "go.mod" && tidyEqual(ours, e.Content):
+		res.Action = UpgradeUnchanged
+		return res, nil, ""
+	case oursHash == baseHash:
+		res.Action = UpgradeUpdated
+		return res, e.Content, ""
+	case theirsHash == baseHash:
+		res.Action = UpgradeKept
+		return res, nil, ""
+	}
+
+	base, _ := g.originalContent(e.Path, baseHash)
+	merged := diff.Merge3(string(base), string(ours), string(e.Content), diff.MergeOptions{
+		OursLabel:   "local",
+		TheirsLabel: "template",
+		KeepOurs:    opts.Reject,
+	})
+
+	if len(merged.Conflicts) == 0 {
+		res.Action = UpgradeMerged
+		return res, []byte(merged.Text), ""
+	}
+
+	res.Action = UpgradeConflict
+	res.Conflicts = len(merged.Conflicts)
+	if !opts.Reject {
+		return res, []byte(merged.Text), ""
+	}
+	res.Reject = e.Path + rejectSuffix
+	return res, []byte(merged.Text), rejectHunks(e.Path, merged.Conflicts)
+}
+
+// rejectHunks formats conflicts as the base-to-template hunks that could
+// not be applied
+func rejectHunks(name string, conflicts []diff.Conflict) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", name, name)
+	for _, c := range conflicts {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", c.Line, len(c.Base), c.Line, len(c.Theirs))
+		for _, l := range c.Base {
+			b.WriteString("-" + terminate(l))
+		}
+		for _, l := range c.Theirs {
+			b.WriteString("+" + terminate(l))
+		}
+	}
+	return b.String()
+}
+
+func terminate(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return line
+	}
+	return line + "\n\\ No newline at end of file\n"
+}
+
+// maxHistory bounds how many commits originalContent inspects per file
+const maxHistory = 100
+
+// originalContent looks through the git history of name for the content
+// recorded in the manifest under hash
+func (g *Generator) originalContent(name, hash string) ([]byte, bool) {
+	dir, ok := g.diskDir()
+	if !ok || hash == "" {
+		return nil, false
+	}
+
+	log := exec.Command("git", "log", fmt.Sprintf("--max-count=%d", maxHistory), "--format=%H", "--", name)
+	log.Dir = dir
+	commits, err := log.Output()
+	if err != nil {
+		return nil, false
+	}
+
+	for _, commit := range strings.Fields(string(commits)) {
+		show := exec.Command("git", "show", commit+":"+name)
+		show.Dir = dir
+		content, err := show.Output()
+		if err != nil {
+			continue
+		}
+		if HashContent(content) == hash {
+			return content, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// VarType is the type of a template variable
+type VarType string
+
+const (
+	VarString VarType = "string"
+	VarInt    VarType = "int"
+	VarBool   VarType = "bool"
+	VarEnum   VarType = "enum"
+	// VarList is a comma-separated list of strings
+	VarList VarType = "list"
+)
+
+// Variable is an extra input declared by a template. Values are kept as
+// strings in Config.Variables and converted to their type for templates.
+type Variable struct {
+	Name string  `yaml:"name"`
+	Type VarType `yaml:"type"`
+	// Default is used when no value is given. Variables without a default
+	// are required, except bools, which default to false, and lists.
+	Default string `yaml:"default"`
+	// Options lists the allowed values of an enum
+	Options []string `yaml:"options"`
+	// Pattern is a regular expression every value must match
+	Pattern string `yaml:"pattern"`
+	Help    string `yaml:"help"`
+	// When is a template pipeline, e.g. `eq .Variables.database "postgres"`;
+	// the variable is only used when it evaluates to true
+	When string `yaml:"when"`
+}
+
+// variableProvider is implemented by templates that declare variables
+type variableProvider interface {
+	Variables() []Variable
+}
+
+// TemplateVariables returns the variables declared by t
+func TemplateVariables(t Template) []Variable {
+	if p, ok := t.(variableProvider); ok {
+		return p.Variables()
+	}
+	return nil
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// check validates the declaration of v
+func (v *Variable) check() error {
+	if !variableName.MatchString(v.Name) {
+		return fmt.Errorf("invalid variable name %q", v.Name)
+	}
+	switch v.Type {
+	case "":
+		v.Type = VarString
+	case VarString, VarInt, VarBool, VarList:
+	case VarEnum:
+		if len(v.Options) == 0 {
+			return fmt.Errorf("enum variable %s has no options", v.Name)
+		}
+	default:
+		return fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+	}
+	if v.Pattern != "" {
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("variable %s has an invalid pattern: %w", v.Name, err)
+		}
+	}
+	if v.Type == VarBool && v.Default == "" {
+		v.Default = "false"
+	}
+	if v.Default != "" {
+		if err := v.Validate(v.Default); err != nil {
+			return fmt.Errorf("default of variable %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate reports whether value is acceptable for v
+func (v Variable) Validate(value string) error {
+	switch v.Type {
+	case VarInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case VarBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+	case VarEnum:
+		found := false
+		for _, o := range v.Options {
+			if o == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%q is not one of %s", value, strings.Join(v.Options, ", "))
+		}
+	}
+
+	if v.Pattern != "" {
+		re := regexp.MustCompile(v.Pattern)
+		values := []string{value}
+		if v.Type == VarList {
+			values = splitList(value)
+		}
+		for _, s := range values {
+			if !re.MatchString(s) {
+				return fmt.Errorf("%q does not match %s", s, v.Pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// typed converts a validated value to the type of v
+func (v Variable) typed(value string) interface{} {
+	switch v.Type {
+	case VarInt:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case VarBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case VarList:
+		return splitList(value)
+	}
+	return value
+}
+
+// zero returns the string form of the zero value of the type of v
+func (v Variable) zero() string {
+	switch v.Type {
+	case VarInt:
+		return "0"
+	case VarBool:
+		return "false"
+	}
+	return ""
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			items = append(items, s)
+		}
+	}
+	return items
+}
+
+// VariablePrompt asks for the value of v, offering def as the default
+type VariablePrompt func(v Variable, def string) (string, error)
+
+// ResolveVariables returns the values of the variables declared by t for
+// cfg. Values from cfg.Variables are used first; missing ones are asked
+// for with prompt, if not nil, or take their default. Variables whose
+// When condition is false are dropped. Undeclared or invalid values and
+// missing required values are errors.
+func ResolveVariables(t Template, cfg Config, prompt VariablePrompt) (map[string]string, error) {
+	decls := TemplateVariables(t)
+
+	declared := make(map[string]bool, len(decls))
+	for _, v := range decls {
+		declared[v.Name] = true
+	}
+	var unknown []string
+	for name := range cfg.Variables {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("template %s does not declare variable(s) %s", t.Name(), strings.Join(unknown, ", "))
+	}
+
+	resolved := make(map[string]string, len(decls))
+	for _, v := range decls {
+		// Conditions see the variables resolved so far
+		c := cfg
+		c.Variables = resolved
+		if v.When != "" {
+			ok, err := evalCondition(v.When, newTemplateData(c, t))
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		value, given := cfg.Variables[v.Name]
+		if !given && prompt != nil {
+			var err error
+			if value, err = prompt(v, v.Default); err != nil {
+				return nil, err
+			}
+		} else if !given {
+			value = v.Default
+		}
+
+		if value == "" && v.Default == "" && v.Type != VarList {
+			return nil, fmt.Errorf("variable %s is required (set it with --var %s=...)", v.Name, v.Name)
+		}
+		if err := v.Validate(value); err != nil {
+			return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		resolved[v.Name] = value
+	}
+
+	if len(resolved) == 0 {
+		return nil, nil
+	}
+	return resolved, nil
+}
+
+// parseCondition parses the template pipeline expr as a condition
+func parseCondition(expr string, data templateData) (*template.Template, error) {
+	tmpl, err := template.New("when").
+		Funcs(data.funcs()).
+		Option("missingkey=zero").
+		Parse("{{if " + expr + "}}true{{end}}")
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	return tmpl, nil
+}
+
+// evalCondition evaluates the template pipeline expr against data
+func evalCondition(expr string, data templateData) (bool, error) {
+	tmpl, err := parseCondition(expr, data)
+	if err != nil {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("failed to evaluate condition %q: %w", expr, err)
+	}
+	return buf.String() == "true", nil
+}
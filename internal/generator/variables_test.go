@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// varTemplate returns a template declaring vars
+func varTemplate(t *testing.T, vars ...Variable) Template {
+	t.Helper()
+	for i := range vars {
+		if err := vars[i].check(); err != nil {
+			t.Fatalf("check %s: %v", vars[i].Name, err)
+		}
+	}
+	return &DirTemplate{Manifest: Manifest{Name: "vars", Entrypoint: ".", Variables: vars}}
+}
+
+func TestResolveVariables(t *testing.T) {
+	tmpl := varTemplate(t,
+		Variable{Name: "database", Type: VarEnum, Options: []string{"none", "postgres"}, Default: "none"},
+		Variable{Name: "pool", Type: VarInt, Default: "10", When: `eq .Variables.database "postgres"`},
+		Variable{Name: "metrics", Type: VarBool},
+		Variable{Name: "tags", Type: VarList, Pattern: `^[a-z]+$`},
+		Variable{Name: "owner", Pattern: `^[a-z]+$`, When: ".IncludeCI"},
+	)
+
+	tests := []struct {
+		name    string
+		ci      bool
+		vars    map[string]string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"database": "none", "metrics": "false", "tags": ""},
+		},
+		{
+			name: "condition on earlier variable",
+			vars: map[string]string{"database": "postgres"},
+			want: map[string]string{"database": "postgres", "pool": "10", "metrics": "false", "tags": ""},
+		},
+		{
+			name: "given values",
+			ci:   true,
+			vars: map[string]string{"database": "postgres", "pool": "3", "metrics": "true", "tags": "a, b", "owner": "ops"},
+			want: map[string]string{"database": "postgres", "pool": "3", "metrics": "true", "tags": "a, b", "owner": "ops"},
+		},
+		{
+			name: "value of dropped variable is ignored",
+			vars: map[string]string{"pool": "3"},
+			want: map[string]string{"database": "none", "metrics": "false", "tags": ""},
+		},
+		{
+			name:    "undeclared",
+			vars:    map[string]string{"region": "eu", "color": "red"},
+			wantErr: "does not declare variable(s) color, region",
+		},
+		{
+			name:    "required",
+			ci:      true,
+			wantErr: "variable owner is required",
+		},
+		{
+			name:    "invalid enum",
+			vars:    map[string]string{"database": "mysql"},
+			wantErr: `"mysql" is not one of none, postgres`,
+		},
+		{
+			name:    "invalid int",
+			vars:    map[string]string{"database": "postgres", "pool": "many"},
+			wantErr: `"many" is not an integer`,
+		},
+		{
+			name:    "list item not matching pattern",
+			vars:    map[string]string{"tags": "a,B"},
+			wantErr: `"B" does not match`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Name: "demo", ModulePath: "example.com/demo", IncludeCI: tt.ci, Variables: tt.vars}
+			got, err := ResolveVariables(tmpl, cfg, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVariablesPrompt(t *testing.T) {
+	tmpl := varTemplate(t,
+		Variable{Name: "port", Type: VarInt, Default: "8080"},
+		Variable{Name: "region"},
+	)
+
+	var asked []string
+	prompt := func(v Variable, def string) (string, error) {
+		asked = append(asked, v.Name+"="+def)
+		if v.Name == "region" {
+			return "eu", nil
+		}
+		return def, nil
+	}
+	cfg := Config{Name: "demo", Variables: map[string]string{"port": "9000"}}
+	got, err := ResolveVariables(tmpl, cfg, prompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"port": "9000", "region": "eu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// Only missing values are asked for
+	if want := []string{"region="}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("asked %v, want %v", asked, want)
+	}
+}
+
+func TestResolveVariablesNone(t *testing.T) {
+	got, err := ResolveVariables(varTemplate(t), Config{Name: "demo"}, nil)
+	if err != nil || got != nil {
+		t.Errorf("got %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestEvalCondition(t *testing.T) {
+	tmpl := varTemplate(t,
+		Variable{Name: "database", Type: VarEnum, Options: []string{"none", "postgres"}, Default: "none"},
+		Variable{Name: "replicas", Type: VarInt, Default: "1"},
+		Variable{Name: "metrics", Type: VarBool},
+	)
+	cfg := Config{
+		Name:          "demo",
+		ModulePath:    "example.com/demo/v2",
+		IncludeDocker: true,
+		Variables:     map[string]string{"database": "postgres", "replicas": "3", "metrics": "true"},
+	}
+	data := newTemplateData(cfg, tmpl)
+
+	tests := []struct {
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{expr: ".IncludeDocker", want: true},
+		{expr: ".IncludeCI", want: false},
+		{expr: "and .IncludeDocker (not .IncludeCI)", want: true},
+		{expr: `eq .Variables.database "postgres"`, want: true},
+		{expr: `eq .Variables.database "none"`, want: false},
+		{expr: "gt .Variables.replicas 1", want: true},
+		{expr: ".Variables.metrics", want: true},
+		{expr: `eq .MajorVersion 2`, want: true},
+		// Undeclared variables are missing, not errors
+		{expr: ".Variables.unknown", want: false},
+		{expr: "eq .IncludeDocker", wantErr: true},
+		{expr: "{{", wantErr: true},
+		{expr: ".NoSuchField", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := evalCondition(tt.expr, data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("evalCondition(%q) err = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("evalCondition(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// knownModule is a module release goscaffold was tested with
+type knownModule struct {
+	Version string
+	// Go is the go directive of the release's go.mod
+	Go string
+}
+
+// knownVersions pins the modules imported by the built-in templates, and
+// those custom templates require without a version, to releases goscaffold
+// was tested with
+var knownVersions = map[string]knownModule{
+	"github.com/go-chi/chi/v5": {"v5.1.0", "1.14"},
+	"github.com/spf13/cobra":   {"v1.8.1", "1.15"},
+	"google.golang.org/grpc":   {"v1.64.1", "1.19"},
+}
+
+// KnownVersion returns the version goscaffold pins module path to
+func KnownVersion(path string) (string, bool) {
+	m, ok := knownVersions[path]
+	return m.Version, ok
+}
+
+// requirementProvider is implemented by templates whose code imports other
+// modules
+type requirementProvider interface {
+	// Requires lists module paths, optionally pinned as path@version
+	Requires() []string
+}
+
+// TemplateRequirements returns the modules required by t, sorted by path
+func TemplateRequirements(t Template) ([]module.Version, error) {
+	p, ok := t.(requirementProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	var reqs []module.Version
+	for _, r := range p.Requires() {
+		m, err := parseRequirement(r)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.Name(), err)
+		}
+		reqs = append(reqs, m)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Path < reqs[j].Path })
+	return reqs, nil
+}
+
+// parseRequirement parses path or path@version, looking up the version of
+// a bare path in the version table
+func parseRequirement(s string) (module.Version, error) {
+	path, version, pinned := strings.Cut(s, "@")
+	if !pinned {
+		v, ok := KnownVersion(path)
+		if !ok {
+			return module.Version{}, fmt.Errorf("no known version of %s; require it as %s@<version>", path, path)
+		}
+		version = v
+	}
+
+	m := module.Version{Path: path, Version: version}
+	if err := module.Check(m.Path, m.Version); err != nil {
+		return module.Version{}, fmt.Errorf("invalid requirement %s: %w", s, err)
+	}
+	return m, nil
+}
+
+// WithOffline makes go mod tidy resolve modules without network access:
+// from proxyDir, a directory laid out like a module proxy (GOPROXY=file://),
+// or from the module cache when proxyDir is empty
+func WithOffline(proxyDir string) Option {
+	return func(g *Generator) {
+		g.offline = true
+		g.proxyDir = proxyDir
+	}
+}
+
+// goEnv returns the environment go mod tidy runs with
+func (g *Generator) goEnv() ([]string, error) {
+	if !g.offline {
+		return nil, nil
+	}
+
+	proxy := "off"
+	if g.proxyDir != "" {
+		dir, err := filepath.Abs(g.proxyDir)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("module proxy directory %s does not exist", g.proxyDir)
+		}
+		proxy = "file://" + filepath.ToSlash(dir)
+		if !strings.HasPrefix(proxy, "file:///") {
+			// Windows paths start with a drive letter
+			proxy = "file:///" + strings.TrimPrefix(proxy, "file://")
+		}
+	}
+	// The checksum database cannot be reached either; go.sum is filled
+	// from the hashes of the downloaded modules
+	return []string{"GOPROXY=" + proxy, "GOSUMDB=off", "GOFLAGS=-mod=mod"}, nil
+}
+
+// tidyModule runs go mod tidy in the project directory
+func (g *Generator) tidyModule() error {
+	dir, ok := g.diskDir()
+	if !ok {
+		// Nothing on disk to tidy, e.g. when writing an archive
+		g.warn("go mod tidy only runs when the project is written to a directory; skipping it")
+		return nil
+	}
+
+	g.step("Running go mod tidy...")
+
+	env, err := g.goEnv()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return g.recordTidied()
+}
+
+// recordTidied records go.mod and go.sum as go mod tidy left them, so the
+// manifest hashes the files on disk
+func (g *Generator) recordTidied() error {
+	r, ok := g.out.(ReadFileFS)
+	if !ok {
+		return nil
+	}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := r.ReadFile(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		g.record(name, data)
+	}
+	return nil
+}
+
+// tidyEqual reports whether the go.mod files a and b only differ in what go
+// mod tidy changes: layout, comments and indirect requirements
+func tidyEqual(a, b []byte) bool {
+	fa, errA := modfile.Parse("go.mod", a, nil)
+	fb, errB := modfile.Parse("go.mod", b, nil)
+	if errA != nil || errB != nil {
+		return bytes.Equal(a, b)
+	}
+	return modSummary(fa) == modSummary(fb)
+}
+
+// modSummary lists the directives of f that go mod tidy keeps, one per
+// line in sorted order
+func modSummary(f *modfile.File) string {
+	var lines []string
+	if f.Module != nil {
+		lines = append(lines, "module "+f.Module.Mod.Path)
+	}
+	if f.Go != nil {
+		lines = append(lines, "go "+f.Go.Version)
+	}
+	if f.Toolchain != nil {
+		lines = append(lines, "toolchain "+f.Toolchain.Name)
+	}
+	for _, r := range f.Require {
+		if !r.Indirect {
+			lines = append(lines, "require "+r.Mod.String())
+		}
+	}
+	for _, r := range f.Replace {
+		lines = append(lines, "replace "+r.Old.String()+" => "+r.New.String())
+	}
+	for _, e := range f.Exclude {
+		lines = append(lines, "exclude "+e.Mod.String())
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
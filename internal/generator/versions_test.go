@@ -0,0 +1,47 @@
+package generator
+
+import "testing"
+
+func TestTidyEqual(t *testing.T) {
+	generated := "module example.com/demo\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.1\n"
+	tests := []struct {
+		name    string
+		current string
+		want    bool
+	}{
+		{"identical", generated, true},
+		{
+			"tidied",
+			"module example.com/demo\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.1\n\nrequire (\n\tgithub.com/inconshreveable/mousetrap v1.1.0 // indirect\n\tgithub.com/spf13/pflag v1.0.5 // indirect\n)\n",
+			true,
+		},
+		{
+			"regrouped",
+			"module example.com/demo\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.1\n)\n",
+			true,
+		},
+		{
+			"other version",
+			"module example.com/demo\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.9.0\n",
+			false,
+		},
+		{
+			"extra direct requirement",
+			"module example.com/demo\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.1\n\tgithub.com/google/uuid v1.6.0\n)\n",
+			false,
+		},
+		{
+			"other go version",
+			"module example.com/demo\n\ngo 1.23\n\nrequire github.com/spf13/cobra v1.8.1\n",
+			false,
+		},
+		{"unparsable", "module example.com/demo\nbogus\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tidyEqual([]byte(tt.current), []byte(generated)); got != tt.want {
+				t.Errorf("tidyEqual = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
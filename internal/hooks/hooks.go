@@ -0,0 +1,187 @@
+// Package hooks runs the commands that templates and users attach to
+// project generation.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTimeout limits hooks that do not set their own timeout
+const DefaultTimeout = 2 * time.Minute
+
+// Stage tells when a hook runs
+type Stage string
+
+const (
+	// Pre hooks run in the project directory before any file is written
+	Pre Stage = "pre"
+	// Post hooks run in the project directory once it is complete
+	Post Stage = "post"
+)
+
+// Hook is a shell command run before or after generation
+type Hook struct {
+	// Name describes the hook in progress output; defaults to Run
+	Name string `yaml:"name"`
+	// Run is the command, executed with sh -c (cmd /C on Windows)
+	Run string `yaml:"run"`
+	// When is a template pipeline, e.g. `.InitGit`; the hook only runs
+	// when it evaluates to true
+	When string `yaml:"when"`
+	// Timeout stops the hook after the given duration, e.g. 30s
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// UnmarshalYAML accepts a plain command next to the full mapping
+func (h *Hook) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		h.Run = node.Value
+		return nil
+	}
+	type plain Hook
+	return node.Decode((*plain)(h))
+}
+
+// String returns the name of the hook, or its command
+func (h Hook) String() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Run
+}
+
+// Check validates the declaration of h
+func (h Hook) Check() error {
+	if h.Run == "" {
+		return fmt.Errorf("hook %q has no command", h.Name)
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("hook %s has a negative timeout", h)
+	}
+	return nil
+}
+
+// Hooks are the hooks of each stage, in the order they run
+type Hooks struct {
+	Pre  []Hook `yaml:"pre"`
+	Post []Hook `yaml:"post"`
+}
+
+// Empty reports whether there are no hooks at all
+func (h Hooks) Empty() bool {
+	return len(h.Pre) == 0 && len(h.Post) == 0
+}
+
+// Stage returns the hooks of stage s
+func (h Hooks) Stage(s Stage) []Hook {
+	if s == Pre {
+		return h.Pre
+	}
+	return h.Post
+}
+
+// Check validates every hook
+func (h Hooks) Check() error {
+	for _, hook := range append(append([]Hook(nil), h.Pre...), h.Post...) {
+		if err := hook.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Append adds the hooks of o after those of h
+func (h Hooks) Append(o Hooks) Hooks {
+	return Hooks{
+		Pre:  append(append([]Hook(nil), h.Pre...), o.Pre...),
+		Post: append(append([]Hook(nil), h.Post...), o.Post...),
+	}
+}
+
+// Around wraps inner in outer: the pre hooks of outer run first and its
+// post hooks last
+func Around(outer, inner Hooks) Hooks {
+	return Hooks{
+		Pre:  append(append([]Hook(nil), outer.Pre...), inner.Pre...),
+		Post: append(append([]Hook(nil), inner.Post...), outer.Post...),
+	}
+}
+
+// Run executes h in dir with env added to the environment. Output goes to
+// stdout and stderr; if they are nil it is captured and returned as part
+// of the error when the hook fails.
+func Run(h Hook, dir string, env []string, stdout, stderr io.Writer) error {
+	_, err := run(h, dir, env, stdout, stderr)
+	return err
+}
+
+// Capture executes h like Run with nil writers and also returns the last
+// part of its combined output
+func Capture(h Hook, dir string, env []string) ([]byte, error) {
+	return run(h, dir, env, nil, nil)
+}
+
+func run(h Hook, dir string, env []string, stdout, stderr io.Writer) ([]byte, error) {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, h.Run)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	// Do not wait forever on children that keep the output open
+	cmd.WaitDelay = time.Second
+
+	var captured limitedBuffer
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if stdout == nil {
+		cmd.Stdout = &captured
+	}
+	if stderr == nil {
+		cmd.Stderr = &captured
+	}
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil && len(captured.buf) > 0 {
+		return captured.buf, fmt.Errorf("%w\n%s", err, captured.buf)
+	}
+	return captured.buf, err
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// maxCaptured bounds the output kept for error messages
+const maxCaptured = 16 << 10
+
+// limitedBuffer keeps the last maxCaptured bytes written to it
+type limitedBuffer struct {
+	buf []byte
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > maxCaptured {
+		b.buf = b.buf[len(b.buf)-maxCaptured:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,122 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run with cmd /C on Windows")
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		run        string
+		wantOut    string
+		wantErr    string
+		wantStderr string
+	}{
+		{run: "true"},
+		{run: "echo $HOOK_TEST; pwd", wantOut: "set\n" + dir + "\n"},
+		{run: "echo oops >&2; false", wantStderr: "oops\n", wantErr: "exit status 1"},
+		{run: "exit 3", wantErr: "exit status 3"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		err := Run(Hook{Run: tt.run}, dir, []string{"HOOK_TEST=set"}, &stdout, &stderr)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("Run(%q) = %v, want %q", tt.run, err, tt.wantErr)
+		}
+		if got := stdout.String(); got != tt.wantOut {
+			t.Errorf("Run(%q) stdout = %q, want %q", tt.run, got, tt.wantOut)
+		}
+		if got := stderr.String(); got != tt.wantStderr {
+			t.Errorf("Run(%q) stderr = %q, want %q", tt.run, got, tt.wantStderr)
+		}
+	}
+}
+
+func TestCapture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run with cmd /C on Windows")
+	}
+	dir := t.TempDir()
+
+	out, err := Capture(Hook{Run: "echo out; echo err >&2; touch made"}, dir, nil)
+	if err != nil || string(out) != "out\nerr\n" {
+		t.Errorf("Capture = %q, %v, want the combined output", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "made")); err != nil {
+		t.Errorf("hook did not run in %s: %v", dir, err)
+	}
+
+	// The output of a failing hook is part of its error
+	out, err = Capture(Hook{Run: "echo broken; false"}, dir, nil)
+	if err == nil || err.Error() != "exit status 1\nbroken\n" || string(out) != "broken\n" {
+		t.Errorf("Capture of a failing hook = %q, %v", out, err)
+	}
+
+	_, err = Capture(Hook{Run: "sleep 5", Timeout: 50 * time.Millisecond}, dir, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Errorf("Capture of a slow hook = %v, want a timeout", err)
+	}
+}
+
+func TestHooksYAML(t *testing.T) {
+	var h Hooks
+	src := "pre:\n  - make deps\npost:\n  - name: vet\n    run: go vet ./...\n    when: .IncludeTests\n    timeout: 30s\n"
+	if err := yaml.Unmarshal([]byte(src), &h); err != nil {
+		t.Fatal(err)
+	}
+	want := Hooks{
+		Pre:  []Hook{{Run: "make deps"}},
+		Post: []Hook{{Name: "vet", Run: "go vet ./...", When: ".IncludeTests", Timeout: 30 * time.Second}},
+	}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("got %+v, want %+v", h, want)
+	}
+	if err := h.Check(); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+	if got := h.Post[0].String(); got != "vet" {
+		t.Errorf("String() = %q, want the name", got)
+	}
+
+	for _, bad := range []Hooks{
+		{Pre: []Hook{{Name: "empty"}}},
+		{Post: []Hook{{Run: "true", Timeout: -time.Second}}},
+	} {
+		if err := bad.Check(); err == nil {
+			t.Errorf("Check(%+v) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestAround(t *testing.T) {
+	outer := Hooks{Pre: []Hook{{Run: "outer pre"}}, Post: []Hook{{Run: "outer post"}}}
+	inner := Hooks{Pre: []Hook{{Run: "inner pre"}}, Post: []Hook{{Run: "inner post"}}}
+
+	var got []string
+	h := Around(outer, inner)
+	for _, stage := range []Stage{Pre, Post} {
+		for _, hook := range h.Stage(stage) {
+			got = append(got, hook.Run)
+		}
+	}
+	want := []string{"outer pre", "inner pre", "inner post", "outer post"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Around = %v, want %v", got, want)
+	}
+	if !(Hooks{}).Empty() || h.Empty() {
+		t.Error("Empty is wrong")
+	}
+}
@@ -0,0 +1,152 @@
+// Package spec reads project specs, the files behind goscaffold new --from,
+// and publishes the JSON Schema they are validated against.
+package spec
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// schemaURL is the $id of the published schema
+const schemaURL = "https://github.com/azrakarakaya1/goscaffold/spec.schema.json"
+
+// Spec describes a project completely, so it can be generated without
+// prompts, flags or configuration files
+type Spec struct {
+	Name         string `yaml:"name"`
+	ModulePath   string `yaml:"modulePath"`
+	GitHub       string `yaml:"github"`
+	Host         string `yaml:"host"`
+	Vanity       bool   `yaml:"vanity"`
+	ModulePrefix string `yaml:"modulePrefix"`
+	Template     string `yaml:"template"`
+	TemplateDir  string `yaml:"templateDir"`
+	Makefile     bool   `yaml:"makefile"`
+	Docker       bool   `yaml:"docker"`
+	CI           bool   `yaml:"ci"`
+	Lint         bool   `yaml:"lint"`
+	PreCommit    bool   `yaml:"precommit"`
+	Tests        bool   `yaml:"tests"`
+	Git          bool   `yaml:"git"`
+	Tidy         bool   `yaml:"tidy"`
+	License      string `yaml:"license"`
+	Author       string `yaml:"author"`
+	GoVersion    string `yaml:"goVersion"`
+	// Variables holds template variables; numbers and booleans are kept in
+	// their literal form
+	Variables map[string]string `yaml:"variables"`
+}
+
+// Schema returns the JSON Schema of a spec
+func Schema() []byte {
+	return append([]byte(nil), schemaJSON...)
+}
+
+// ResolvedModulePath returns the module path described by the spec
+func (s *Spec) ResolvedModulePath() string {
+	switch {
+	case s.ModulePath != "":
+		return s.ModulePath
+	case s.ModulePrefix != "":
+		return strings.TrimSuffix(s.ModulePrefix, "/") + "/" + s.Name
+	case s.GitHub != "":
+		return generator.HostModulePath(s.Host, s.GitHub, s.Name)
+	default:
+		return s.Name
+	}
+}
+
+// Parse reads a spec in YAML or JSON from r, validates it against the
+// schema and fills in the schema defaults. name identifies the source in
+// error messages.
+func Parse(name string, r io.Reader) (*Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate a JSON form of the document, since YAML is a superset
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	if err := validate(doc); err != nil {
+		return nil, fmt.Errorf("invalid spec %s:\n%w", name, err)
+	}
+
+	var s Spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if s.Template == "" {
+		s.Template = "basic"
+	}
+	if s.Host == "" {
+		s.Host = generator.DefaultHost
+	}
+	if s.License == "" {
+		s.License = "MIT"
+	}
+	return &s, nil
+}
+
+// validate checks doc, as decoded from YAML, against the schema
+func validate(doc interface{}) error {
+	// Round-trip through JSON so the validator sees JSON types
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(schemaURL, bytes.NewReader(schemaJSON)); err != nil {
+		return err
+	}
+	schema, err := c.Compile(schemaURL)
+	if err != nil {
+		return err
+	}
+
+	err = schema.Validate(v)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	// Report the most specific errors, one per line
+	var lines []string
+	for _, e := range ve.BasicOutput().Errors {
+		if e.Error == "" || strings.HasPrefix(e.Error, "doesn't validate with") {
+			continue
+		}
+		loc := e.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", loc, e.Error))
+	}
+	sort.Strings(lines)
+	return errors.New(strings.Join(lines, "\n"))
+}
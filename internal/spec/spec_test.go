@@ -0,0 +1,124 @@
+package spec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	want := &Spec{
+		Name:      "billing",
+		GitHub:    "acme",
+		Host:      "gitlab.com",
+		Template:  "api",
+		Docker:    true,
+		CI:        true,
+		License:   "Apache-2.0",
+		GoVersion: "1.22",
+		Variables: map[string]string{"port": "9090", "metrics": "true", "db": "postgres"},
+	}
+
+	tests := []struct {
+		name, input string
+	}{
+		{"spec.yaml", `name: billing
+github: acme
+host: gitlab.com
+template: api
+docker: true
+ci: true
+license: Apache-2.0
+goVersion: "1.22"
+variables:
+  port: 9090
+  metrics: true
+  db: postgres
+`},
+		{"spec.json", `{
+  "name": "billing",
+  "github": "acme",
+  "host": "gitlab.com",
+  "template": "api",
+  "docker": true,
+  "ci": true,
+  "license": "Apache-2.0",
+  "goVersion": "1.22",
+  "variables": {"port": 9090, "metrics": true, "db": "postgres"}
+}`},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.name, strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Parse(%s) = %+v, want %+v", tt.name, got, want)
+		}
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	got, err := Parse("spec.yaml", strings.NewReader("name: demo\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Spec{Name: "demo", Template: "basic", Host: "github.com", License: "MIT"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			input: "name: 1demo\ndocker: yes please\nextra: 1\n",
+			want: []string{
+				"invalid spec spec.yaml:",
+				"/: additionalProperties 'extra' not allowed",
+				"/docker: expected boolean, but got string",
+				"/name: does not match pattern",
+			},
+		},
+		{input: "{}", want: []string{"/: missing properties: 'name'"}},
+		{input: "name: demo\ngoVersion: latest\n", want: []string{"/goVersion: does not match pattern"}},
+		{input: "name: demo\nvariables: {port: [1]}\n", want: []string{"/variables/port: expected string or number or boolean, but got array"}},
+		{input: "name: demo\nvariables: {my-var: x}\n", want: []string{"/variables/my-var: does not match pattern"}},
+		{input: "", want: []string{"spec.yaml is empty"}},
+		{input: "name: [\n", want: []string{"failed to parse spec.yaml"}},
+	}
+	for _, tt := range tests {
+		_, err := Parse("spec.yaml", strings.NewReader(tt.input))
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", tt.input)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Parse(%q) = %v, want an error containing %q", tt.input, err, want)
+			}
+		}
+	}
+}
+
+func TestResolvedModulePath(t *testing.T) {
+	tests := []struct {
+		spec Spec
+		want string
+	}{
+		{Spec{Name: "demo", ModulePath: "example.com/x", GitHub: "acme"}, "example.com/x"},
+		{Spec{Name: "demo", ModulePrefix: "go.example.com/team/", GitHub: "acme"}, "go.example.com/team/demo"},
+		{Spec{Name: "demo", GitHub: "acme", Host: "github.com"}, "github.com/acme/demo"},
+		{Spec{Name: "demo", GitHub: "/group/sub/", Host: "gitlab.com/"}, "gitlab.com/group/sub/demo"},
+		{Spec{Name: "demo", GitHub: "acme"}, "github.com/acme/demo"},
+		{Spec{Name: "demo"}, "demo"},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.ResolvedModulePath(); got != tt.want {
+			t.Errorf("ResolvedModulePath(%+v) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
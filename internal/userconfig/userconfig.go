@@ -0,0 +1,348 @@
+// Package userconfig loads user defaults and presets for new projects from
+// configuration files and the environment.
+package userconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the per-repository configuration file, found by walking up
+// from the current directory
+const FileName = ".goscaffold.yaml"
+
+// globalFile is the name of the user configuration file inside the
+// goscaffold configuration directory
+const globalFile = "config.yaml"
+
+// envPrefix starts the name of every environment variable read by Load
+const envPrefix = "GOSCAFFOLD_"
+
+// Defaults holds user defaults for new projects. Empty strings and nil
+// toggles are unset and leave the built-in default in place.
+type Defaults struct {
+	// GitHub is the user or organization used in <host>/<user>/<name>
+	GitHub string `yaml:"github"`
+	// Host is the code host of module paths built from GitHub, e.g.
+	// gitlab.com; github.com if empty
+	Host string `yaml:"host"`
+	// ModulePrefix builds module paths as <prefix>/<name> and takes
+	// precedence over GitHub
+	ModulePrefix string `yaml:"modulePrefix"`
+	Template     string `yaml:"template"`
+	// TemplateDir is a template directory used instead of Template
+	TemplateDir string `yaml:"templateDir"`
+
+	Makefile  *bool `yaml:"makefile"`
+	Docker    *bool `yaml:"docker"`
+	CI        *bool `yaml:"ci"`
+	Lint      *bool `yaml:"lint"`
+	PreCommit *bool `yaml:"precommit"`
+	Tests     *bool `yaml:"tests"`
+	Git       *bool `yaml:"git"`
+	Tidy      *bool `yaml:"tidy"`
+	// Interactive set to false skips the prompts of goscaffold new
+	Interactive *bool `yaml:"interactive"`
+
+	License   string `yaml:"license"`
+	Author    string `yaml:"author"`
+	GoVersion string `yaml:"goVersion"`
+}
+
+// Merge overrides the fields of d that are set in o
+func (d *Defaults) Merge(o Defaults) {
+	mergeString(&d.GitHub, o.GitHub)
+	mergeString(&d.Host, o.Host)
+	mergeString(&d.ModulePrefix, o.ModulePrefix)
+	// Template and TemplateDir select the template together
+	if o.Template != "" || o.TemplateDir != "" {
+		d.Template, d.TemplateDir = o.Template, o.TemplateDir
+	}
+	mergeBool(&d.Makefile, o.Makefile)
+	mergeBool(&d.Docker, o.Docker)
+	mergeBool(&d.CI, o.CI)
+	mergeBool(&d.Lint, o.Lint)
+	mergeBool(&d.PreCommit, o.PreCommit)
+	mergeBool(&d.Tests, o.Tests)
+	mergeBool(&d.Git, o.Git)
+	mergeBool(&d.Tidy, o.Tidy)
+	mergeBool(&d.Interactive, o.Interactive)
+	mergeString(&d.License, o.License)
+	mergeString(&d.Author, o.Author)
+	mergeString(&d.GoVersion, o.GoVersion)
+}
+
+func mergeString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+func mergeBool(dst **bool, v *bool) {
+	if v != nil {
+		*dst = v
+	}
+}
+
+// Preset is a named bundle of defaults selected with --preset
+type Preset struct {
+	Name        string `yaml:"-"`
+	Description string `yaml:"description"`
+	Defaults    `yaml:",inline"`
+	// Source is the file or template directory that defines the preset
+	Source string `yaml:"-"`
+}
+
+// Summary describes the settings of the preset in one line
+func (p Preset) Summary() string {
+	var parts []string
+	switch {
+	case p.TemplateDir != "":
+		parts = append(parts, p.TemplateDir)
+	case p.Template != "":
+		parts = append(parts, p.Template)
+	}
+
+	toggles := []struct {
+		name string
+		v    *bool
+	}{
+		{"makefile", p.Makefile}, {"docker", p.Docker}, {"ci", p.CI},
+		{"lint", p.Lint}, {"precommit", p.PreCommit}, {"tests", p.Tests}, {"git", p.Git}, {"tidy", p.Tidy},
+	}
+	for _, t := range toggles {
+		if t.v == nil {
+			continue
+		}
+		if *t.v {
+			parts = append(parts, t.name)
+		} else {
+			parts = append(parts, "no "+t.name)
+		}
+	}
+
+	switch {
+	case p.ModulePrefix != "":
+		parts = append(parts, "module "+p.ModulePrefix+"/<name>")
+	case p.GitHub != "":
+		host := p.Host
+		if host == "" {
+			host = "github.com"
+		}
+		parts = append(parts, "module "+host+"/"+p.GitHub+"/<name>")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// BuiltinSource is the Source of the presets that ship with goscaffold
+const BuiltinSource = "builtin"
+
+// BuiltinPresets returns the presets behind --all-devops and --all-quality
+func BuiltinPresets() []Preset {
+	yes := true
+	return []Preset{
+		{
+			Name:        "devops",
+			Description: "Makefile, Docker and CI workflow",
+			Defaults:    Defaults{Makefile: &yes, Docker: &yes, CI: &yes},
+			Source:      BuiltinSource,
+		},
+		{
+			Name:        "quality",
+			Description: "Linter, pre-commit hooks and tests",
+			Defaults:    Defaults{Lint: &yes, PreCommit: &yes, Tests: &yes},
+			Source:      BuiltinSource,
+		},
+	}
+}
+
+// CheckPresetName rejects the names of the built-in presets, so that
+// configuration files and templates cannot change what --all-devops and
+// --all-quality do
+func CheckPresetName(name string) error {
+	for _, p := range BuiltinPresets() {
+		if p.Name == name {
+			return fmt.Errorf("preset %s is built in and cannot be redefined", name)
+		}
+	}
+	return nil
+}
+
+// Settings is the user configuration in effect for a directory
+type Settings struct {
+	Defaults
+	// Presets holds the presets defined in the configuration files
+	Presets map[string]Preset
+	// Hooks holds the hooks of the user configuration file
+	Hooks hooks.Hooks
+	// RepoHooks holds the hooks of the .goscaffold.yaml found, which comes
+	// with the repository and must be approved before they run
+	RepoHooks hooks.Hooks
+	// RepoFile is the path of that .goscaffold.yaml, if any
+	RepoFile string
+	// Files lists the configuration files that were read
+	Files []string
+}
+
+// file is the layout of a configuration file
+type file struct {
+	Defaults `yaml:",inline"`
+	Presets  map[string]Preset `yaml:"presets"`
+	Hooks    hooks.Hooks       `yaml:"hooks"`
+}
+
+// Load returns the settings for a project created from dir. The user
+// configuration file is read first, then the nearest .goscaffold.yaml in
+// dir or its parents, then GOSCAFFOLD_* environment variables; later
+// sources override earlier ones, including presets of the same name.
+func Load(dir string) (*Settings, error) {
+	s := &Settings{Presets: make(map[string]Preset)}
+
+	var paths []string
+	if p, err := GlobalPath(); err == nil {
+		paths = append(paths, p)
+	}
+	repoFile, ok := findRepoFile(dir)
+	if ok {
+		paths = append(paths, repoFile)
+	}
+
+	for _, p := range paths {
+		f, err := loadFile(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := f.Hooks.Check(); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		s.Merge(f.Defaults)
+		if p == repoFile {
+			s.RepoHooks, s.RepoFile = f.Hooks, p
+		} else {
+			s.Hooks = f.Hooks
+		}
+		for name, preset := range f.Presets {
+			if err := CheckPresetName(name); err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+			preset.Name, preset.Source = name, p
+			s.Presets[name] = preset
+		}
+		s.Files = append(s.Files, p)
+	}
+
+	env, err := FromEnv(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	s.Merge(env)
+	return s, nil
+}
+
+// GlobalPath returns the path of the user configuration file,
+// $XDG_CONFIG_HOME/goscaffold/config.yaml or its platform equivalent
+func GlobalPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		if dir, err = os.UserConfigDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "goscaffold", globalFile), nil
+}
+
+// findRepoFile walks up from dir looking for a .goscaffold.yaml
+func findRepoFile(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		p := filepath.Join(dir, FileName)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// loadFile reads the YAML configuration file at path. Unknown keys are
+// rejected so typos do not go unnoticed.
+func loadFile(path string) (file, error) {
+	var cf file
+
+	f, err := os.Open(path)
+	if err != nil {
+		return cf, err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cf); err != nil && !errors.Is(err, io.EOF) {
+		return cf, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cf, nil
+}
+
+// FromEnv reads defaults from GOSCAFFOLD_* variables through lookup
+func FromEnv(lookup func(string) (string, bool)) (Defaults, error) {
+	var d Defaults
+
+	strs := map[string]*string{
+		"GITHUB":        &d.GitHub,
+		"HOST":          &d.Host,
+		"MODULE_PREFIX": &d.ModulePrefix,
+		"TEMPLATE":      &d.Template,
+		"TEMPLATE_DIR":  &d.TemplateDir,
+		"LICENSE":       &d.License,
+		"AUTHOR":        &d.Author,
+		"GO_VERSION":    &d.GoVersion,
+	}
+	for name, dst := range strs {
+		if v, ok := lookup(envPrefix + name); ok {
+			*dst = strings.TrimSpace(v)
+		}
+	}
+
+	bools := map[string]**bool{
+		"MAKEFILE":    &d.Makefile,
+		"DOCKER":      &d.Docker,
+		"CI":          &d.CI,
+		"LINT":        &d.Lint,
+		"PRECOMMIT":   &d.PreCommit,
+		"TESTS":       &d.Tests,
+		"GIT":         &d.Git,
+		"TIDY":        &d.Tidy,
+		"INTERACTIVE": &d.Interactive,
+	}
+	for name, dst := range bools {
+		v, ok := lookup(envPrefix + name)
+		if !ok || strings.TrimSpace(v) == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return d, fmt.Errorf("invalid value %q for %s%s: want true or false", v, envPrefix, name)
+		}
+		*dst = &b
+	}
+	return d, nil
+}
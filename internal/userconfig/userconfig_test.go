@@ -0,0 +1,200 @@
+package userconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configDirs sets up a user configuration directory and a repository with
+// a nested working directory, clears GOSCAFFOLD_* variables and writes the
+// given configuration files; empty ones are not written. It returns the
+// working directory and the path of the repository file.
+func configDirs(t *testing.T, global, repo string) (string, string) {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, envPrefix) {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	work := filepath.Join(root, "repo", "services", "billing")
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repoFile := filepath.Join(root, "repo", FileName)
+	files := map[string]string{
+		filepath.Join(root, "config", "goscaffold", globalFile): global,
+		repoFile: repo,
+	}
+	for p, content := range files {
+		if content == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return work, repoFile
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const global = `github: alice
+template: api
+docker: true
+lint: true
+license: Apache-2.0
+presets:
+  svc:
+    description: global service
+    template: api
+  lib:
+    template: library
+`
+	const repo = `github: acme
+templateDir: ./templates/service
+docker: false
+presets:
+  svc:
+    description: repo service
+    template: grpc
+`
+
+	tests := []struct {
+		name   string
+		global string
+		repo   string
+		env    map[string]string
+		check  func(t *testing.T, s *Settings, repoFile string)
+	}{
+		{
+			name:   "user configuration",
+			global: global,
+			check: func(t *testing.T, s *Settings, repoFile string) {
+				wantDefaults(t, s, "alice", "api", "", true)
+				if s.RepoFile != "" || len(s.Files) != 1 {
+					t.Errorf("files = %v, repo file %q, want only the user configuration", s.Files, s.RepoFile)
+				}
+			},
+		},
+		{
+			name:   "repository file overrides user configuration",
+			global: global,
+			repo:   repo,
+			check: func(t *testing.T, s *Settings, repoFile string) {
+				// The template directory replaces the template
+				wantDefaults(t, s, "acme", "", "./templates/service", false)
+				if s.Lint == nil || !*s.Lint || s.License != "Apache-2.0" {
+					t.Errorf("lint = %v, license = %q, want the user configuration", s.Lint, s.License)
+				}
+				if p := s.Presets["svc"]; p.Template != "grpc" || p.Description != "repo service" || p.Source != repoFile {
+					t.Errorf("preset svc = %+v, want the one of %s", p, repoFile)
+				}
+				if p := s.Presets["lib"]; p.Template != "library" {
+					t.Errorf("preset lib = %+v, want the user one", p)
+				}
+				if s.RepoFile != repoFile || len(s.Files) != 2 {
+					t.Errorf("files = %v, repo file %q, want both", s.Files, s.RepoFile)
+				}
+			},
+		},
+		{
+			name:   "environment overrides files",
+			global: global,
+			repo:   repo,
+			env:    map[string]string{"GOSCAFFOLD_GITHUB": " bob ", "GOSCAFFOLD_TEMPLATE": "cli", "GOSCAFFOLD_DOCKER": "1", "GOSCAFFOLD_LINT": ""},
+			check: func(t *testing.T, s *Settings, repoFile string) {
+				wantDefaults(t, s, "bob", "cli", "", true)
+				// Empty variables are unset
+				if s.Lint == nil || !*s.Lint {
+					t.Errorf("lint = %v, want the user configuration", s.Lint)
+				}
+			},
+		},
+		{
+			name: "environment only",
+			env:  map[string]string{"GOSCAFFOLD_TEMPLATE_DIR": "/tpl", "GOSCAFFOLD_DOCKER": "false"},
+			check: func(t *testing.T, s *Settings, repoFile string) {
+				wantDefaults(t, s, "", "", "/tpl", false)
+				if len(s.Files) != 0 {
+					t.Errorf("files = %v, want none", s.Files)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			work, repoFile := configDirs(t, tt.global, tt.repo)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			s, err := Load(work)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.check(t, s, repoFile)
+		})
+	}
+}
+
+// wantDefaults checks the GitHub user, template and Docker toggle of s
+func wantDefaults(t *testing.T, s *Settings, github, template, templateDir string, docker bool) {
+	t.Helper()
+	if s.GitHub != github || s.Template != template || s.TemplateDir != templateDir {
+		t.Errorf("github, template, templateDir = %q, %q, %q, want %q, %q, %q",
+			s.GitHub, s.Template, s.TemplateDir, github, template, templateDir)
+	}
+	if s.Docker == nil || *s.Docker != docker {
+		t.Errorf("docker = %v, want %v", s.Docker, docker)
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	work, repoFile := configDirs(t, "hooks:\n  post: [go vet ./...]\n", "hooks:\n  pre: [make deps]\n")
+	s, err := Load(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Repository hooks are kept apart, for approval
+	if len(s.Hooks.Post) != 1 || len(s.Hooks.Pre) != 0 {
+		t.Errorf("Hooks = %+v, want the post hook of the user configuration", s.Hooks)
+	}
+	if len(s.RepoHooks.Pre) != 1 || s.RepoHooks.Pre[0].Run != "make deps" || s.RepoFile != repoFile {
+		t.Errorf("RepoHooks = %+v from %q, want the pre hook of %s", s.RepoHooks, s.RepoFile, repoFile)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  string
+		repo    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown key", repo: "gihtub: acme\n", wantErr: "field gihtub not found"},
+		{name: "invalid toggle", global: "docker: maybe\n", wantErr: "failed to parse"},
+		{name: "built-in preset", repo: "presets:\n  devops:\n    docker: false\n", wantErr: "preset devops is built in"},
+		{name: "invalid hook", global: "hooks:\n  post: [{name: x}]\n", wantErr: `hook "x" has no command`},
+		{name: "invalid environment toggle", env: map[string]string{"GOSCAFFOLD_TESTS": "sometimes"}, wantErr: `invalid value "sometimes" for GOSCAFFOLD_TESTS`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			work, _ := configDirs(t, tt.global, tt.repo)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if _, err := Load(work); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package scaffold generates Go projects from goscaffold templates. It is
+// the library behind the goscaffold command, for programs that create
+// projects without running the binary:
+//
+//	g := scaffold.New(scaffold.Config{
+//		Name:       "orders",
+//		ModulePath: "github.com/acme/orders",
+//		Template:   "api",
+//		GoVersion:  scaffold.DetectGoVersion(),
+//	})
+//	res, err := g.Generate()
+//
+// Unlike the command, the package never writes to stdout or stderr: the
+// progress of a generation and the output of its hooks are sent to the
+// Reporter given WithReporter, if any, and summed up in the Result.
+package scaffold
+
+import (
+	"path/filepath"
+
+	"github.com/azrakarakaya1/goscaffold/internal/generator"
+	"github.com/azrakarakaya1/goscaffold/internal/hooks"
+)
+
+// Config holds the project generation configuration
+type Config = generator.Config
+
+// Template describes a project template. Templates may also declare
+// variables, rules, hooks, required modules and a minimum Go version by
+// implementing the methods Variables() []Variable, Rules() []Rule,
+// Hooks() Hooks, Requires() []string and MinGoVersion() string.
+type Template = generator.Template
+
+// File is a single file produced by a template
+type File = generator.File
+
+// Variable is an extra input declared by a template
+type Variable = generator.Variable
+
+// VarType is the type of a template variable
+type VarType = generator.VarType
+
+// Variable types
+const (
+	VarString = generator.VarString
+	VarInt    = generator.VarInt
+	VarBool   = generator.VarBool
+	VarEnum   = generator.VarEnum
+	VarList   = generator.VarList
+)
+
+// Rule limits a template path to projects for which a condition holds
+type Rule = generator.Rule
+
+// Hook is a shell command run before or after generation
+type Hook = hooks.Hook
+
+// Hooks are the hooks of each stage, in the order they run
+type Hooks = hooks.Hooks
+
+// HookStage tells when a hook runs
+type HookStage = hooks.Stage
+
+// Hook stages
+const (
+	PreHook  = hooks.Pre
+	PostHook = hooks.Post
+)
+
+// HookApproval decides whether the hooks of a template that does not ship
+// with goscaffold may run; an error stops generation
+type HookApproval = generator.HookApproval
+
+// StepError reports which generation step failed
+type StepError = generator.StepError
+
+// WriteFS is an output a project can be written to
+type WriteFS = generator.WriteFS
+
+// MemFS is an in-memory output
+type MemFS = generator.MemFS
+
+// Entry is a directory or file produced by Generate
+type Entry = generator.Entry
+
+// HookRun records a hook considered by Generate
+type HookRun = generator.HookRun
+
+// Event reports the progress of a generator
+type Event = generator.Event
+
+// EventKind identifies what an Event reports
+type EventKind = generator.EventKind
+
+// Event kinds
+const (
+	EventStepStarted  = generator.EventStepStarted
+	EventStepFinished = generator.EventStepFinished
+	EventFileWritten  = generator.EventFileWritten
+	EventHookOutput   = generator.EventHookOutput
+	EventWarning      = generator.EventWarning
+)
+
+// Reporter receives the events of a generator
+type Reporter = generator.Reporter
+
+// ReporterFunc adapts a function to a Reporter
+type ReporterFunc = generator.ReporterFunc
+
+// ConflictPolicy decides what happens to a file that already exists
+type ConflictPolicy = generator.ConflictPolicy
+
+// Conflict policies
+const (
+	ConflictSkip      = generator.ConflictSkip
+	ConflictOverwrite = generator.ConflictOverwrite
+	ConflictBackup    = generator.ConflictBackup
+	ConflictPrompt    = generator.ConflictPrompt
+	ConflictMerge     = generator.ConflictMerge
+)
+
+// ConflictRule applies a policy to the files matching a pattern
+type ConflictRule = generator.ConflictRule
+
+// ConflictResolver decides for ConflictPrompt
+type ConflictResolver = generator.ConflictResolver
+
+// ConflictOptions control how existing files are treated
+type ConflictOptions = generator.ConflictOptions
+
+// Conflict records how an existing file was treated
+type Conflict = generator.Conflict
+
+// DefaultHost is the code host module paths are built for by default
+const DefaultHost = generator.DefaultHost
+
+// Register adds t to the templates available by name
+func Register(t Template) error {
+	return generator.Register(t)
+}
+
+// Lookup returns the registered template called name
+func Lookup(name string) (Template, bool) {
+	return generator.Lookup(name)
+}
+
+// Templates returns the registered templates, built-in ones first
+func Templates() []Template {
+	return generator.Templates()
+}
+
+// LoadTemplateDir loads a template from a directory with a template.yaml
+// manifest
+func LoadTemplateDir(dir string) (Template, error) {
+	return generator.LoadDirTemplate(dir)
+}
+
+// ResolveTemplate returns the template loaded from dir when set, otherwise
+// the registered template called name, falling back to the template search
+// path of the user
+func ResolveTemplate(name, dir string) (Template, error) {
+	return generator.ResolveTemplate(name, dir)
+}
+
+// NewMemFS returns an empty in-memory output
+func NewMemFS() *MemFS {
+	return generator.NewMemFS()
+}
+
+// HostModulePath returns the module path of the repository name owned by
+// owner on host, e.g. gitlab.com/group/name
+func HostModulePath(host, owner, name string) string {
+	return generator.HostModulePath(host, owner, name)
+}
+
+// ValidateModulePath checks p against the rules of the go command
+func ValidateModulePath(p string) error {
+	return generator.ValidateModulePath(p)
+}
+
+// ParseGoVersion validates a Go version such as 1.22, 1.22.5 or go1.22.5
+// and returns it without the go prefix
+func ParseGoVersion(s string) (string, error) {
+	return generator.ParseGoVersion(s)
+}
+
+// DetectGoVersion returns the version of the go command on the host
+func DetectGoVersion() string {
+	return generator.DetectGoVersion()
+}
+
+// Option configures a Generator
+type Option = generator.Option
+
+// WithOutput makes the generator write the project to fsys, whose root
+// becomes the project root
+func WithOutput(fsys WriteFS) Option {
+	return generator.WithOutput(fsys)
+}
+
+// WithReporter sends the events of the generator to r
+func WithReporter(r Reporter) Option {
+	return generator.WithReporter(r)
+}
+
+// WithConflicts makes Generate write into the project directory if it
+// already exists, treating existing files as opts says. Such generation is
+// not atomic: a failure leaves the files written so far in place.
+func WithConflicts(opts ConflictOptions) Option {
+	return generator.WithConflicts(opts)
+}
+
+// WithToolVersion records the version of the calling program in the
+// project manifest
+func WithToolVersion(version string) Option {
+	return generator.WithToolVersion(version)
+}
+
+// WithHooks adds hooks that run around those of the template
+func WithHooks(h Hooks) Option {
+	return generator.WithHooks(h)
+}
+
+// WithoutHooks disables all hooks
+func WithoutHooks() Option {
+	return generator.WithoutHooks()
+}
+
+// WithHookApproval sets the function that approves template hooks. Without
+// it, templates with hooks can only be generated WithoutHooks.
+func WithHookApproval(approve HookApproval) Option {
+	return generator.WithHookApproval(approve)
+}
+
+// WithOffline makes go mod tidy resolve modules from proxyDir, a directory
+// laid out like a module proxy, or from the module cache when it is empty
+func WithOffline(proxyDir string) Option {
+	return generator.WithOffline(proxyDir)
+}
+
+// Generator generates a single project
+type Generator struct {
+	g *generator.Generator
+}
+
+// New creates a Generator that writes the project to a directory named
+// after the project in the current working directory, unless another
+// output is given with WithOutput
+func New(cfg Config, opts ...Option) *Generator {
+	return &Generator{g: generator.New(cfg, opts...)}
+}
+
+// Result describes a generated project
+type Result struct {
+	// Dir is the absolute project directory, or "" when the project was
+	// not written to the operating system
+	Dir string
+	// Entries are the directories and files written, in order
+	Entries []Entry
+	// Hooks are the hooks considered, in the order they ran
+	Hooks []HookRun
+	// Conflicts are the existing files met WithConflicts
+	Conflicts []Conflict
+}
+
+// Files returns the slash-separated paths of the files written
+func (r *Result) Files() []string {
+	var files []string
+	for _, e := range r.Entries {
+		if !e.Dir {
+			files = append(files, e.Path)
+		}
+	}
+	return files
+}
+
+// Generate creates the project. The Result is returned even on error; its
+// entries then tell how far generation got. On the operating system a new
+// project directory is only moved into place once every step succeeded,
+// so nothing is left on disk unless a post hook failed. With WithConflicts
+// an existing directory is written in place instead: files written before
+// a failure stay there, and Result.Conflicts tells which existing files
+// were touched.
+func (g *Generator) Generate() (*Result, error) {
+	err := g.g.Generate()
+
+	res := &Result{Entries: g.g.Entries(), Hooks: g.g.HookRuns(), Conflicts: g.g.Conflicts()}
+	if dir, ok := g.g.Dir(); ok {
+		if abs, absErr := filepath.Abs(dir); absErr == nil {
+			dir = abs
+		}
+		res.Dir = dir
+	}
+	return res, err
+}
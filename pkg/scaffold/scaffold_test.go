@@ -0,0 +1,38 @@
+package scaffold_test
+
+import (
+	"testing"
+
+	"github.com/azrakarakaya1/goscaffold/pkg/scaffold"
+)
+
+func TestGenerateMemFS(t *testing.T) {
+	mem := scaffold.NewMemFS()
+	cfg := scaffold.Config{Name: "demo", ModulePath: "example.com/demo", Template: "basic"}
+
+	res, err := scaffold.New(cfg, scaffold.WithOutput(mem)).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if res.Dir != "" {
+		t.Errorf("Dir = %q, want none for a MemFS", res.Dir)
+	}
+
+	files := make(map[string]bool)
+	for _, f := range res.Files() {
+		files[f] = true
+		if _, err := mem.ReadFile(f); err != nil {
+			t.Errorf("%s listed but not written: %v", f, err)
+		}
+	}
+	for _, want := range []string{"go.mod", "main.go", "README.md", ".gitignore", ".goscaffold.json"} {
+		if !files[want] {
+			t.Errorf("Files() = %v, missing %s", res.Files(), want)
+		}
+	}
+
+	mod, _ := mem.ReadFile("go.mod")
+	if want := "module example.com/demo\n"; len(mod) < len(want) || string(mod[:len(want)]) != want {
+		t.Errorf("go.mod starts with %q, want %q", mod, want)
+	}
+}